@@ -0,0 +1,255 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// Checks the `magic`, `assert` and `warn` tags of a field that has just been
+// read.
+//
+// `magic` expects the field to hold the given constant, written either as an
+// integer (`magic:"0x89504E47"`) or, for byte arrays, as a hex or quoted
+// string (`magic:"'BING'"`). `assert` takes a comparison operator followed by
+// such a constant, e.g. `assert:"<=2"` or `assert:"=='BING'"`. `warn` takes
+// the same form, e.g. `warn:"!=0"` for a deprecated value, but a mismatch is
+// only recorded as a warning.
+func (p *Parser) checkAssertions(fieldval reflect.Value, fieldtyp reflect.StructField, offset uint64) error {
+	if magic := getTag(fieldtyp, "magic"); len(magic) > 0 {
+		ok, err := p.compareLiteral("magic", "==", magic, fieldval, fieldtyp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := p.fail(fieldval, ErrAssertionFailed, nil, "Magic mismatch in '%v %v' at offset %v: expected %v, got %v.", fieldtyp.Name, fieldtyp.Type, offset, formatLiteral(magic, fieldval), formatValue(magic, fieldval)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if assert := getTag(fieldtyp, "assert"); len(assert) > 0 {
+		op, lit, err := p.parseComparison("assert", assert)
+		if err != nil {
+			return err
+		}
+		ok, err := p.compareLiteral("assert", op, lit, fieldval, fieldtyp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := p.fail(fieldval, ErrAssertionFailed, nil, "Assertion failed for '%v %v' at offset %v: expected %v %v, got %v.", fieldtyp.Name, fieldtyp.Type, offset, op, formatLiteral(lit, fieldval), formatValue(lit, fieldval)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Like `assert`, but only records a warning
+	if warn := getTag(fieldtyp, "warn"); len(warn) > 0 {
+		op, lit, err := p.parseComparison("warn", warn)
+		if err != nil {
+			return err
+		}
+		ok, err := p.compareLiteral("warn", op, lit, fieldval, fieldtyp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			p.warn(ErrAssertionFailed, "Unexpected value in '%v %v' at offset %v: expected %v %v, got %v.", fieldtyp.Name, fieldtyp.Type, offset, op, formatLiteral(lit, fieldval), formatValue(lit, fieldval))
+		}
+	}
+
+	return p.checkRange(fieldval, fieldtyp, offset)
+}
+
+// Splits a tag such as `assert:"<=2"` into the operator and the constant.
+func (p *Parser) parseComparison(tag, value string) (op, lit string, err error) {
+	for _, candidate := range comparisonOps {
+		if strings.HasPrefix(value, candidate) {
+			return candidate, strings.TrimSpace(value[len(candidate):]), nil
+		}
+	}
+	err = p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag: %v. Expected a comparison operator followed by a constant.", tag, value)
+	return
+}
+
+// Checks the range given by the `min` and `max` tags of an integer field, or
+// by a `valid` tag combining both, as in `valid:"1..65535"`. Either end of the
+// range may be left out.
+func (p *Parser) checkRange(fieldval reflect.Value, fieldtyp reflect.StructField, offset uint64) error {
+	mintag, maxtag := "min", "max"
+	lo, hi := getTag(fieldtyp, "min"), getTag(fieldtyp, "max")
+	if valid := getTag(fieldtyp, "valid"); len(valid) > 0 {
+		var ok bool
+		if lo, hi, ok = strings.Cut(valid, ".."); !ok {
+			return p.errorf(ErrInvalidTag, nil, "Invalid value for `valid` tag: %v. Expected a range such as 1..255.", valid)
+		}
+		mintag, maxtag = "valid", "valid"
+	}
+
+	if len(lo) > 0 {
+		ok, err := p.compareLiteral(mintag, ">=", lo, fieldval, fieldtyp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := p.fail(fieldval, ErrAssertionFailed, nil, "Value out of range in '%v %v' at offset %v: %v is less than %v.", fieldtyp.Name, fieldtyp.Type, offset, formatValue(lo, fieldval), lo); err != nil {
+				return err
+			}
+		}
+	}
+	if len(hi) > 0 {
+		ok, err := p.compareLiteral(maxtag, "<=", hi, fieldval, fieldtyp)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return p.fail(fieldval, ErrAssertionFailed, nil, "Value out of range in '%v %v' at offset %v: %v is greater than %v.", fieldtyp.Name, fieldtyp.Type, offset, formatValue(hi, fieldval), hi)
+		}
+	}
+	return nil
+}
+
+// Compares the field's value against a constant from a tag.
+func (p *Parser) compareLiteral(tag, op, lit string, fieldval reflect.Value, fieldtyp reflect.StructField) (bool, error) {
+	var cmp int
+
+	switch fieldval.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		expected, err := strconv.ParseInt(lit, 0, 64)
+		if err != nil {
+			return false, p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag: %v. Expected an integer.", tag, lit)
+		}
+		cmp = compareInts(fieldval.Int(), expected)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		expected, err := strconv.ParseUint(lit, 0, 64)
+		if err != nil {
+			return false, p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag: %v. Expected an integer.", tag, lit)
+		}
+		cmp = compareUints(fieldval.Uint(), expected)
+
+	case reflect.Array:
+		if fieldval.Type().Elem().Kind() != reflect.Uint8 {
+			return false, p.errorf(ErrUnsupportedType, nil, "Unable to check `%v` tag on '%v %v'. Only integers and byte arrays are supported.", tag, fieldtyp.Name, fieldtyp.Type)
+		}
+		if op != "==" && op != "!=" {
+			return false, p.errorf(ErrInvalidTag, nil, "Invalid operator in `%v` tag: %v. Byte arrays can only be compared with == and !=.", tag, op)
+		}
+		expected, ok := parseBytesLiteral(lit)
+		if !ok || len(expected) != fieldval.Len() {
+			return false, p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag: %v. Expected %v bytes as a hex or quoted string.", tag, lit, fieldval.Len())
+		}
+		cmp = bytes.Compare(arrayBytes(fieldval), expected)
+
+	default:
+		return false, p.errorf(ErrUnsupportedType, nil, "Unable to check `%v` tag on '%v %v'. Only integers and byte arrays are supported.", tag, fieldtyp.Name, fieldtyp.Type)
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default: // ">="
+		return cmp >= 0, nil
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+var bytesLiteralCache sync.Map // map[string][]byte
+
+// Parses 'text', "text" or 0x-prefixed hex into a byte slice. Literals come
+// from tags, so they're parsed once and the slice is shared: it must not be
+// modified.
+func parseBytesLiteral(lit string) ([]byte, bool) {
+	if b, ok := bytesLiteralCache.Load(lit); ok {
+		return b.([]byte), true
+	}
+	var b []byte
+	if n := len(lit); n >= 2 && (lit[0] == '\'' || lit[0] == '"') && lit[n-1] == lit[0] {
+		b = []byte(lit[1 : n-1])
+	} else if strings.HasPrefix(lit, "0x") || strings.HasPrefix(lit, "0X") {
+		var err error
+		if b, err = hex.DecodeString(lit[2:]); err != nil {
+			return nil, false
+		}
+	} else {
+		return nil, false
+	}
+	bytesLiteralCache.Store(lit, b)
+	return b, true
+}
+
+// Returns the bytes of a byte array, sharing its memory when it's
+// addressable.
+func arrayBytes(val reflect.Value) []byte {
+	if val.CanAddr() {
+		return val.Bytes()
+	}
+	b := make([]byte, val.Len())
+	reflect.Copy(reflect.ValueOf(b), val)
+	return b
+}
+
+func formatLiteral(lit string, fieldval reflect.Value) string {
+	if fieldval.Kind() == reflect.Array {
+		b, _ := parseBytesLiteral(lit)
+		return formatBytes(b)
+	}
+	return lit
+}
+
+// Formats the field's value the way the constant it's compared with is
+// written, so that mismatches are easy to spot.
+func formatValue(lit string, fieldval reflect.Value) string {
+	if fieldval.Kind() == reflect.Array {
+		return formatBytes(arrayBytes(fieldval))
+	}
+	hexlit := strings.HasPrefix(lit, "0x") || strings.HasPrefix(lit, "0X")
+	if fieldval.CanInt() {
+		if hexlit && fieldval.Int() >= 0 {
+			return "0x" + strings.ToUpper(strconv.FormatInt(fieldval.Int(), 16))
+		}
+		return strconv.FormatInt(fieldval.Int(), 10)
+	}
+	if hexlit {
+		return "0x" + strings.ToUpper(strconv.FormatUint(fieldval.Uint(), 16))
+	}
+	return strconv.FormatUint(fieldval.Uint(), 10)
+}
+
+func formatBytes(b []byte) string {
+	return fmt.Sprintf("% X", b)
+}
@@ -0,0 +1,134 @@
+package bingo
+
+import (
+	"testing"
+)
+
+type MagicStruct struct {
+	Signature [4]byte `magic:"'BING'"`
+	Version   uint16  `assert:"<=123"`
+	Reserved  [2]int16
+	NChans    int16 `assert:"!=0"`
+}
+
+func TestMagicAndAssert(t *testing.T) {
+	s := MagicStruct{}
+	p := newParserData(fixedSizeData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if p.offset != 12 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestMagicMismatch(t *testing.T) {
+	s := struct {
+		Signature [4]byte `magic:"0x89504E47"`
+	}{}
+	p := newParserData(fixedSizeData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Magic mismatch in 'Signature [4]uint8' at offset 0: expected 89 50 4E 47, got 42 49 4E 47." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 4 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestMagicMismatchInt(t *testing.T) {
+	s := struct {
+		Signature uint32 `magic:"0x474E4942"`
+		Version   uint16 `magic:"0x7C"`
+	}{}
+	p := newParserData(fixedSizeData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Magic mismatch in 'Version uint16' at offset 4: expected 0x7C, got 0x7B." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestAssertFailure(t *testing.T) {
+	s := struct {
+		Signature [4]byte
+		Version   uint16 `assert:">=200"`
+	}{}
+	p := newParserData(fixedSizeData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Assertion failed for 'Version uint16' at offset 4: expected >= 200, got 123." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestAssertInvalidOperator(t *testing.T) {
+	s := struct {
+		Signature [4]byte `assert:"<'BING'"`
+	}{}
+	p := newParserData(fixedSizeData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid operator in `assert` tag: <. Byte arrays can only be compared with == and !=." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestRangeTags(t *testing.T) {
+	data := []byte{5, 0xFF, 0xFF, 10, 0}
+	s := struct {
+		Count uint8  `min:"1" max:"8"`
+		Delta int16  `valid:"-5..5"`
+		Port  uint16 `valid:"1.."`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Count != 5 || s.Delta != -1 || s.Port != 10 {
+		t.Error("Error parsing range-checked fields:", s)
+	}
+}
+
+func TestRangeFailure(t *testing.T) {
+	tests := []struct {
+		data []byte
+		msg  string
+	}{
+		{[]byte{0, 1, 0}, "Value out of range in 'Count uint8' at offset 0: 0 is less than 1."},
+		{[]byte{1, 0x10, 0x27}, "Value out of range in 'Port uint16' at offset 1: 0x2710 is greater than 0x1000."},
+	}
+	for _, test := range tests {
+		s := struct {
+			Count uint8  `min:"1"`
+			Port  uint16 `valid:"1..0x1000"`
+		}{}
+		p := newParserData(test.data)
+
+		if err := p.EmitReadStruct(&s); err != nil {
+			if perr, ok := err.(*ParseError); !ok || perr.Error() != test.msg {
+				t.Error("Incorrect error:", err)
+			}
+		} else {
+			t.Error()
+		}
+	}
+}
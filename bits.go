@@ -0,0 +1,119 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// BitReader is a bit-granular reader over the parser's stream, obtained with
+// Parser.BitReader. Bytes are pulled from the stream one at a time, so the
+// parser's offset always counts a partially consumed byte as read.
+//
+// The struct parser discards unread bits before reading any field that isn't
+// tagged with `bits`, so hooks may leave the reader unaligned when they hand
+// control back.
+type BitReader struct {
+	p        *Parser
+	cur      byte
+	nbits    uint // number of unread bits left in cur
+	lsbFirst bool
+}
+
+// BitReader returns the parser's bit reader positioned at the current offset.
+// Bit order follows the LSBFirst parse option.
+func (p *Parser) BitReader() *BitReader {
+	return &p.bits
+}
+
+// ReadBits reads n bits (at most 64) and returns them as the low bits of the
+// result.
+func (b *BitReader) ReadBits(n uint) (uint64, error) {
+	if n > 64 {
+		return 0, errors.New("bingo: can't read more than 64 bits at once")
+	}
+
+	var value uint64
+	var buf [1]byte
+	for i := uint(0); i < n; i++ {
+		if b.nbits == 0 {
+			if _, err := io.ReadFull(b.p.r, buf[:]); err != nil {
+				return 0, err
+			}
+			b.p.offset++
+			b.cur, b.nbits = buf[0], 8
+		}
+		if b.lsbFirst {
+			bit := b.cur >> (8 - b.nbits) & 1
+			value |= uint64(bit) << i
+		} else {
+			bit := b.cur >> (b.nbits - 1) & 1
+			value = value<<1 | uint64(bit)
+		}
+		b.nbits--
+	}
+	return value, nil
+}
+
+// ReadBit reads a single bit.
+func (b *BitReader) ReadBit() (bool, error) {
+	bit, err := b.ReadBits(1)
+	return bit != 0, err
+}
+
+// Buffered returns the number of unread bits left in the current byte.
+func (b *BitReader) Buffered() uint {
+	return b.nbits
+}
+
+// Align discards the unread bits of the current byte so that the next read
+// starts on a byte boundary.
+func (b *BitReader) Align() {
+	b.nbits = 0
+}
+
+func (p *Parser) alignBits() {
+	p.bits.Align()
+}
+
+// Reads a field tagged with `bits:"N"`. Consecutive bit fields share the
+// underlying bytes; bits are taken from the most significant one first unless
+// the parser was created with the LSBFirst option.
+func (p *Parser) readBitField(fieldval reflect.Value, fieldtyp reflect.StructField, bitskey string) error {
+	nbits, err := strconv.ParseUint(bitskey, 0, 8)
+	if err != nil || nbits == 0 {
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `bits` tag: %v. Expected a positive integer.", bitskey)
+	}
+
+	var maxbits int
+	switch fieldval.Kind() {
+	case reflect.Bool:
+		maxbits = 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		maxbits = fieldval.Type().Bits()
+	default:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. The `bits` tag is only supported on integers and bools.", fieldtyp.Name, fieldtyp.Type)
+	}
+	if int(nbits) > maxbits {
+		return p.errorf(nil, nil, "Error reading field '%v %v'. Can't fit %v bits into it.", fieldtyp.Name, fieldtyp.Type, nbits)
+	}
+
+	value, err := p.bits.ReadBits(uint(nbits))
+	if err != nil {
+		return p.wrapError(err)
+	}
+
+	switch fieldval.Kind() {
+	case reflect.Bool:
+		fieldval.SetBool(value != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// sign-extend
+		shift := 64 - nbits
+		fieldval.SetInt(int64(value<<shift) >> shift)
+	default:
+		fieldval.SetUint(value)
+	}
+	return nil
+}
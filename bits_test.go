@@ -0,0 +1,122 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type IPv4Header struct {
+	Version     uint8 `bits:"4"`
+	IHL         uint8 `bits:"4"`
+	DSCP        uint8 `bits:"6"`
+	ECN         uint8 `bits:"2"`
+	TotalLength uint16
+	Reserved    bool   `bits:"1"`
+	DontFrag    bool   `bits:"1"`
+	MoreFrags   bool   `bits:"1"`
+	FragOffset  uint16 `bits:"13"`
+}
+
+func TestBitFields(t *testing.T) {
+	data := []byte{0x45, 0xB8, 0x00, 0x54, 0x40, 0x01}
+	s := IPv4Header{}
+	p := NewParser(bytes.NewReader(data), BigEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Version == 4 && s.IHL == 5) {
+		t.Error("Error parsing first byte of bit fields:", s)
+	}
+	if !(s.DSCP == 46 && s.ECN == 0) {
+		t.Error("Error parsing second byte of bit fields:", s)
+	}
+	if s.TotalLength != 84 {
+		t.Error("Error parsing byte-aligned field after bit fields:", s.TotalLength)
+	}
+	if !(!s.Reserved && s.DontFrag && !s.MoreFrags && s.FragOffset == 1) {
+		t.Error("Error parsing bit fields spanning bytes:", s)
+	}
+	if p.offset != 6 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestBitFieldsLSBFirst(t *testing.T) {
+	data := []byte{0xF5, 0x03, 0x07}
+	s := struct {
+		Low    uint8 `bits:"3"`
+		High   int8  `bits:"5"`
+		Unused uint8 `bits:"2"`
+		Next   uint8
+	}{}
+	p := NewParser(bytes.NewReader(data), LittleEndian, LSBFirst)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Low == 5 && s.High == -2) {
+		t.Error("Error parsing LSB-first bit fields:", s)
+	}
+	if s.Unused != 3 {
+		t.Error("Error parsing LSB-first bit field in second byte:", s.Unused)
+	}
+	if s.Next != 7 {
+		t.Error("Error parsing byte-aligned field after bit fields:", s.Next)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestBitFieldTooWide(t *testing.T) {
+	s := struct {
+		Value uint8 `bits:"9"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Value uint8'. Can't fit 9 bits into it." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestBitReader(t *testing.T) {
+	data := []byte{0xA7, 0x80, 0xFF}
+	p := NewParser(bytes.NewReader(data), BigEndian, Default)
+	b := p.BitReader()
+
+	if v, err := b.ReadBits(3); err != nil || v != 5 {
+		t.Error("Error reading 3 bits:", v, err)
+	}
+	if b.Buffered() != 5 {
+		t.Error("Invalid number of buffered bits:", b.Buffered())
+	}
+	if v, err := b.ReadBits(6); err != nil || v != 0x0F {
+		t.Error("Error reading bits across a byte boundary:", v, err)
+	}
+	if p.offset != 2 {
+		t.Error("Invalid offset after reading bits:", p.offset)
+	}
+
+	b.Align()
+	s := struct {
+		Tail uint8
+	}{}
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+	if s.Tail != 0xFF {
+		t.Error("Error parsing struct after aligning bit reader:", s.Tail)
+	}
+
+	if _, err := b.ReadBit(); err != io.EOF {
+		t.Error("Expected EOF, got", err)
+	}
+}
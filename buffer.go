@@ -0,0 +1,106 @@
+package bingo
+
+import (
+	"bufio"
+	"io"
+)
+
+// Size of the buffer parsers read through unless told otherwise
+const defaultBufferSize = 4096
+
+// A buffer the parser reads through so that the many small reads of fixed
+// size fields don't each turn into a call to the underlying reader, which
+// may well be a system call
+type bufferedReader struct {
+	bufio.Reader
+	r io.Reader // the underlying reader
+}
+
+// A bufferedReader over a reader that can seek, which can seek as well
+type bufferedSeeker struct {
+	bufferedReader
+	seeker io.Seeker
+}
+
+// Makes the parser read from r, through its buffer of the given size unless
+// size is 0 or r does its own buffering or holds its data in memory, as
+// readers implementing io.ByteReader do.
+func (p *Parser) setReader(r io.Reader, size int) {
+	p.r, p.src, p.bufferSize = r, r, size
+	if _, ok := r.(io.ByteReader); ok || r == nil || size <= 0 {
+		return
+	}
+	b := &p.buffer
+	if b.Size() == size {
+		b.Reset(r)
+	} else {
+		b.Reader = *bufio.NewReaderSize(r, size)
+	}
+	b.r = r
+	if seeker, ok := r.(io.Seeker); ok {
+		b.seeker = seeker
+		p.r = b
+	} else {
+		b.seeker = nil
+		p.r = &b.bufferedReader
+	}
+	p.src = p.r
+}
+
+// Returns the reader r buffers, or r itself if it isn't a buffer.
+func unbuffered(r io.Reader) io.Reader {
+	switch b := r.(type) {
+	case *bufferedReader:
+		return b.r
+	case *bufferedSeeker:
+		return b.r
+	}
+	return r
+}
+
+// Seeks the underlying reader, keeping what's buffered if the new position
+// lies within it.
+func (b *bufferedSeeker) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		if offset >= 0 && offset <= int64(b.Buffered()) {
+			pos, err := b.seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+			b.Discard(int(offset))
+			return pos - int64(b.Buffered()), nil
+		}
+		offset -= int64(b.Buffered())
+	}
+	pos, err := b.seeker.Seek(offset, whence)
+	b.Reset(b.r)
+	return pos, err
+}
+
+// Seeks the reader the parser buffers back to where the parse got, dropping
+// what was read ahead, so that whoever reads from it next picks up from
+// there. Readers that can't seek are left as they are.
+func (p *Parser) syncReader() {
+	b, ok := p.src.(*bufferedSeeker)
+	if !ok || b.Buffered() == 0 {
+		return
+	}
+	if _, err := b.seeker.Seek(-int64(b.Buffered()), io.SeekCurrent); err == nil {
+		b.Reset(b.r)
+	}
+}
+
+// SetBufferSize sets the size of the buffer the parser reads through, 4096
+// bytes by default, or turns buffering off with 0. It's to be called before
+// anything is read.
+//
+// Readers that implement io.ByteReader, such as *bytes.Reader and
+// *bufio.Reader, are read from directly. Others, like files and network
+// connections, are read ahead of what has been parsed so far. Those that
+// implement io.Seeker are seeked back to where the parse got when
+// EmitReadStruct returns, except between records read with Next; the others
+// are left past it, so if such a reader is to be used apart from the parser,
+// turn buffering off or look at Offset to know how far the parse got.
+func (p *Parser) SetBufferSize(size int) {
+	p.setReader(unbuffered(p.src), size)
+}
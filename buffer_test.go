@@ -0,0 +1,79 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// Counts the calls to Read, hiding any other methods but Seek
+type readCounter struct {
+	io.ReadSeeker
+	reads int
+}
+
+func (r *readCounter) Read(b []byte) (int, error) {
+	r.reads++
+	return r.ReadSeeker.Read(b)
+}
+
+type smallFields struct {
+	A, B, C, D uint8
+	E          uint16 `assert:"!=0"`
+	F          uint16 `assert:"!=0"`
+}
+
+func TestBufferedReads(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 0, 6, 0, 0xFF}
+	for _, size := range []int{0, defaultBufferSize} {
+		r := &readCounter{ReadSeeker: bytes.NewReader(data)}
+		p := NewParser(r, LittleEndian, Default)
+		p.SetBufferSize(size)
+		var s smallFields
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		if s != (smallFields{1, 2, 3, 4, 5, 6}) || p.Offset() != 8 {
+			t.Errorf("Incorrect struct: %+v at offset %v", s, p.Offset())
+		}
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if size == 0 && (r.reads != 3 || pos != 8) {
+			t.Errorf("Expected 3 reads up to offset 8 unbuffered. Got %v reads up to %v", r.reads, pos)
+		}
+		if size > 0 && (r.reads != 1 || pos != 8) {
+			t.Errorf("Expected a single read buffered, seeked back to offset 8. Got %v reads up to %v", r.reads, pos)
+		}
+	}
+}
+
+func TestBufferedSeek(t *testing.T) {
+	r := &readCounter{ReadSeeker: bytes.NewReader(archiveData)}
+	s := Archive{}
+	if err := NewParser(r, LittleEndian, Default).EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Dir) != 2 || string(s.Dir[0].Name) != "foo" || string(s.Dir[1].Name) != "b" || s.Trailer != 0xEE {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+}
+
+func TestBufferedNotSeekable(t *testing.T) {
+	s := Archive{}
+	p := NewParser(io.MultiReader(bytes.NewReader(archiveData)), LittleEndian, Default)
+	if err := p.EmitReadStruct(&s); err == nil {
+		t.Error("Expected an error seeking")
+	}
+}
+
+func TestByteReaderNotBuffered(t *testing.T) {
+	r := bytes.NewReader([]byte{1, 2, 3})
+	p := NewParser(r, LittleEndian, Default)
+	var b uint8
+	if _, err := p.EmitReadFixed(&b, reflect.StructField{}, reflect.ValueOf(&b)); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 2 {
+		t.Error("Expected the reader to be read from directly. Left:", r.Len())
+	}
+}
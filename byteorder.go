@@ -0,0 +1,48 @@
+package bingo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// ByteOrder returns the byte order fields are currently read in.
+func (p *Parser) ByteOrder() ByteOrder {
+	return p.byteOrder
+}
+
+// SetByteOrder changes the byte order for the fields read from now on. It can
+// be called from hooks, e.g. `before`, to switch the order mid-struct.
+func (p *Parser) SetByteOrder(byteOrder ByteOrder) {
+	p.byteOrder = byteOrder
+}
+
+// Switches the parser's byte order according to the mark that was just read
+// into a byte array tagged with `byteorder`. The tag lists the little-endian
+// mark followed by the big-endian one, as hex or quoted strings:
+//
+//	Order [2]byte `byteorder:"'II','MM'"`         // TIFF
+//	BOM   [2]byte `byteorder:"0xFFFE,0xFEFF"`     // UTF-16
+//
+// The new order applies to the rest of the parse.
+func (p *Parser) readByteOrderMark(fieldval reflect.Value, fieldtyp reflect.StructField, offset uint64, orderkey string) error {
+	if fieldval.Kind() != reflect.Array || fieldval.Type().Elem().Kind() != reflect.Uint8 {
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. The `byteorder` tag requires a byte array.", fieldtyp.Name, fieldtyp.Type)
+	}
+	littlestr, bigstr, ok := strings.Cut(orderkey, ",")
+	little, okLittle := parseBytesLiteral(littlestr)
+	big, okBig := parseBytesLiteral(bigstr)
+	if !ok || !okLittle || !okBig {
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `byteorder` tag: %v. Expected the little- and big-endian marks as hex or quoted strings.", orderkey)
+	}
+
+	switch mark := arrayBytes(fieldval); {
+	case bytes.Equal(mark, little):
+		p.byteOrder = LittleEndian
+	case bytes.Equal(mark, big):
+		p.byteOrder = BigEndian
+	default:
+		return p.fail(fieldval, ErrAssertionFailed, nil, "Unknown byte order mark in '%v %v' at offset %v: %v. Expected %v or %v.", fieldtyp.Name, fieldtyp.Type, offset, formatBytes(mark), formatBytes(little), formatBytes(big))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package bingo
+
+import (
+	"testing"
+)
+
+type tiffHeader struct {
+	Order     [2]byte `byteorder:"'II','MM'"`
+	Magic     uint16  `magic:"42"`
+	IFDOffset uint32
+}
+
+func TestByteOrderMark(t *testing.T) {
+	tests := [][]byte{
+		{'I', 'I', 42, 0, 8, 0, 0, 0},
+		{'M', 'M', 0, 42, 0, 0, 0, 8},
+	}
+	for _, data := range tests {
+		s := tiffHeader{}
+		p := newParserData(data)
+
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Error(err)
+		}
+
+		if s.IFDOffset != 8 {
+			t.Error("Error parsing field after byte order mark:", s.IFDOffset)
+		}
+		if p.offset != 8 {
+			t.Error("Invalid offset:", p.offset)
+		}
+	}
+}
+
+func TestByteOrderMarkUnknown(t *testing.T) {
+	s := tiffHeader{}
+	p := newParserData([]byte{'X', 'X', 0, 42})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Unknown byte order mark in 'Order [2]uint8' at offset 0: 58 58. Expected 49 49 or 4D 4D." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
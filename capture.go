@@ -0,0 +1,74 @@
+package bingo
+
+import (
+	"reflect"
+)
+
+// A field that records something about a sibling field as that one is read,
+// instead of being read itself
+type capture struct {
+	index int
+	tag   string
+}
+
+// Tags of the capture fields. `offsetof` stores the offset at which the named
+// field starts and `sizeref` the number of bytes it took up in the stream,
+// not counting padding:
+//
+//	DataOffset uint64 `offsetof:"Data"`
+//	DataSize   uint32 `sizeref:"Data"`
+var captureTags = []string{"offsetof", "sizeref"}
+
+// Returns the capture fields of a struct type by the name of the field they
+// refer to.
+func structCaptures(typ reflect.Type) map[string][]capture {
+	captures := make(map[string][]capture)
+	for i := 0; i < typ.NumField(); i++ {
+		for _, tag := range captureTags {
+			if target := getTag(typ.Field(i), tag); len(target) > 0 {
+				captures[target] = append(captures[target], capture{i, tag})
+			}
+		}
+	}
+	return captures
+}
+
+func isCapture(fieldtyp reflect.StructField) bool {
+	for _, tag := range captureTags {
+		if len(getTag(fieldtyp, tag)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Fills in the capture fields for a field that started at offset and ended
+// where the parser is now.
+func (p *Parser) fillCaptures(val reflect.Value, captures []capture, offset uint64) error {
+	for _, c := range captures {
+		fieldval := val.Field(c.index)
+		var value uint64
+		switch c.tag {
+		case "offsetof":
+			value = offset
+		case "sizeref":
+			value = p.offset - offset
+		}
+
+		switch fieldval.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if fieldval.OverflowUint(uint64(value)) {
+				return p.errorf(nil, nil, "Error setting field '%v %v'. Value %v from `%v` tag overflows it.", val.Type().Field(c.index).Name, fieldval.Type(), value, c.tag)
+			}
+			fieldval.SetUint(uint64(value))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if fieldval.OverflowInt(int64(value)) {
+				return p.errorf(nil, nil, "Error setting field '%v %v'. Value %v from `%v` tag overflows it.", val.Type().Field(c.index).Name, fieldval.Type(), value, c.tag)
+			}
+			fieldval.SetInt(int64(value))
+		default:
+			return p.errorf(ErrUnsupportedType, nil, "Error setting field '%v %v'. Fields tagged with `%v` must be integers.", val.Type().Field(c.index).Name, fieldval.Type(), c.tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package bingo
+
+import (
+	"testing"
+)
+
+func TestOffsetOf(t *testing.T) {
+	data := []byte{2, 'h', 'i', 5, 0, 0, 0, 0, 0, 'x'}
+	s := struct {
+		NameOffset uint8 `offsetof:"Name"`
+		Length     uint8
+		Name       string `len:"Length"`
+		DataOffset int64  `offsetof:"Data"`
+		Pos        uint8
+		Data       byte `offset:"Pos"`
+		Next       uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.NameOffset != 1 {
+		t.Error("Invalid offset of Name:", s.NameOffset)
+	}
+	if s.DataOffset != 5 {
+		t.Error("Invalid offset of Data:", s.DataOffset)
+	}
+	if p.offset != 5 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOffsetOfOverflow(t *testing.T) {
+	data := make([]byte, 300)
+	s := struct {
+		Header     [256]byte
+		DataOffset uint8 `offsetof:"Data"`
+		Data       uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error setting field 'DataOffset uint8'. Value 256 from `offsetof` tag overflows it." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestSizeRef(t *testing.T) {
+	data := []byte{'a', 'b', 0, 1, 2, 3, 0}
+	s := struct {
+		NameSize    uint16  `sizeref:"Name"`
+		Name        string  `terminator:"0x00"`
+		Items       []uint8 `terminator:"0x00"`
+		ItemsOffset uint8   `offsetof:"Items"`
+		ItemsSize   int     `sizeref:"Items"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.NameSize != 3 {
+		t.Error("Invalid size of Name:", s.NameSize)
+	}
+	if s.ItemsOffset != 3 || s.ItemsSize != 4 {
+		t.Error("Invalid offset or size of Items:", s.ItemsOffset, s.ItemsSize)
+	}
+	if p.offset != 7 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
@@ -0,0 +1,87 @@
+package bingo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ParseErrors is returned by EmitReadStruct with the CollectErrors option when
+// one or more problems were found. The errors are in the order they were
+// found; if the parse couldn't go on, the error that stopped it comes last.
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%v (and %v more errors)", errs[0], len(errs)-1)
+}
+
+// Unwrap lets errors.Is and errors.As look at each of the errors.
+func (errs ParseErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+// Reports an error that doesn't keep the parse from going on. The OnError
+// handler gets to decide about it first. Otherwise, with the CollectErrors
+// option the error is recorded instead, and fieldval, if valid, is reset to
+// its zero value. Returns nil if parsing may continue.
+func (p *Parser) fail(fieldval reflect.Value, kind, cause error, msg string, args ...interface{}) error {
+	perr := p.parseError(kind, cause, fmt.Sprintf(msg, args...))
+	if p.handleError(perr, fieldval) {
+		return nil
+	}
+	if !p.collect {
+		return perr
+	}
+	p.errors = append(p.errors, perr)
+	if fieldval.IsValid() && fieldval.CanSet() {
+		fieldval.Set(reflect.Zero(fieldval.Type()))
+	}
+	return nil
+}
+
+// Reads a struct inside a size-limited region with the CollectErrors option.
+// Since the size of the region is known, a parse error inside of it is
+// recorded and the rest of the region skipped, unless the data ran out.
+func (p *Parser) readRegionCollecting(val reflect.Value, limit_r *io.LimitedReader) error {
+	depth, nframes, byteOrder, start, nlimits := p.depth, len(p.stack), p.byteOrder, p.regionStart, p.nlimits
+	err := p.emitReadStruct(buildPtr(val))
+	if err == nil {
+		return nil
+	}
+	perr, ok := err.(*ParseError)
+	if !ok || errors.Is(perr, ErrUnexpectedEOF) {
+		return err
+	}
+	p.errors = append(p.errors, perr)
+	val.Set(reflect.Zero(val.Type()))
+
+	// Back to the state the region was entered in
+	p.depth, p.stack, p.byteOrder = depth, p.stack[:nframes], byteOrder
+	p.r, p.regionStart = limit_r, start
+	p.popLimits(nlimits)
+	p.alignBits()
+	return p.skipBytes(uint64(limit_r.N))
+}
+
+// Combines the recorded errors with the one that stopped the parse, if any.
+func (p *Parser) collectedErrors(err error) error {
+	if len(p.errors) == 0 {
+		return err
+	}
+	errs := p.errors
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		errs = append(errs, perr)
+	} else if err != nil {
+		errs = append(errs, p.parseError(nil, err, err.Error()))
+	}
+	return errs
+}
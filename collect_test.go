@@ -0,0 +1,88 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type collectChunk struct {
+	Kind  uint8 `assert:"<10"`
+	Value uint16
+	Ext   struct {
+		Size uint8
+		Data []byte `len:"Missing"`
+	} `if:"Kind==2"`
+}
+
+type collectFile struct {
+	Magic  [2]byte `magic:"'CF'"`
+	Count  uint8   `max:"2"`
+	Size1  uint8
+	First  collectChunk `size:"Size1"`
+	Size2  uint8
+	Second collectChunk `size:"Size2"`
+	Size3  uint8
+	Third  collectChunk `size:"Size3"`
+	End    uint8
+}
+
+func TestCollectErrors(t *testing.T) {
+	data := []byte{'X', 'F', 3,
+		3, 12, 1, 0, // assertion fails, value still read
+		4, 2, 2, 0, 0, // reference to a missing field, rest of the chunk skipped
+		3, 1, 3, 0,
+		0xEE}
+	s := collectFile{}
+	p := NewParser(bytes.NewReader(data), LittleEndian, CollectErrors)
+
+	err := p.EmitReadStruct(&s)
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatal("Expected ParseErrors, got", err)
+	}
+	if len(errs) != 4 {
+		t.Fatal("Invalid number of errors:", errs)
+	}
+	if !errors.Is(errs[0], ErrAssertionFailed) || errs[0].FieldPath != "Magic" {
+		t.Error("Invalid first error:", errs[0], errs[0].FieldPath)
+	}
+	if !errors.Is(errs[1], ErrAssertionFailed) || errs[1].FieldPath != "Count" {
+		t.Error("Invalid second error:", errs[1], errs[1].FieldPath)
+	}
+	if !errors.Is(errs[2], ErrAssertionFailed) || errs[2].FieldPath != "First.Kind" || errs[2].Offset != 5 {
+		t.Error("Invalid third error:", errs[2], errs[2].FieldPath, errs[2].Offset)
+	}
+	if !errors.Is(errs[3], ErrTagReference) || errs[3].FieldPath != "Second.Ext.Data" {
+		t.Error("Invalid fourth error:", errs[3], errs[3].FieldPath)
+	}
+	if !errors.Is(err, ErrTagReference) {
+		t.Error("Expected the errors to match ErrTagReference")
+	}
+
+	if s.Magic != [2]byte{} || s.Count != 0 || s.First.Kind != 0 || s.First.Value != 1 {
+		t.Error("Expected failed fields to be zeroed:", s)
+	}
+	if s.Second.Kind != 0 || s.Third.Kind != 1 || s.Third.Value != 3 || s.End != 0xEE {
+		t.Error("Error parsing fields after errors:", s)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestCollectErrorsTruncated(t *testing.T) {
+	data := []byte{'X', 'F', 1, 10, 1, 0}
+	s := collectFile{}
+	p := NewParser(bytes.NewReader(data), LittleEndian, CollectErrors)
+
+	err := p.EmitReadStruct(&s)
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatal("Expected two errors, got", err)
+	}
+	if !errors.Is(errs[1], io.ErrUnexpectedEOF) {
+		t.Error("Expected the parse to stop at the end of data:", errs[1])
+	}
+}
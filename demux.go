@@ -0,0 +1,79 @@
+package bingo
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Reads a run of records of different types that are stored one after another,
+// each preceded by a type code. The field carrying the `demux` tag is only a
+// marker; its `len` tag gives the number of records, and the method named by
+// the `demux` tag routes each record to one of the slice fields of the struct
+// by returning that field's name. Those fields are tagged with `demux:"-"`
+// so that they aren't read on their own:
+//
+//	Count    uint16
+//	_        struct{}       `len:"Count" demux:"Route()"`
+//	Comments []CommentChunk `demux:"-"`
+//	Images   []ImageChunk   `demux:"-"`
+//
+//	func (f *File) Route(p *bingo.Parser, kind uint) string
+//
+// Each record is parsed as an element of the chosen slice and appended to it.
+// The type code is a uint8 unless another integer type is given after the
+// method, as in `demux:"Route(),uint16"`.
+func (p *Parser) readDemux(fieldtyp reflect.StructField, ptrval reflect.Value, demuxkey string) error {
+	storage := "uint8"
+	if idx := strings.IndexByte(demuxkey, ','); idx >= 0 {
+		demuxkey, storage = demuxkey[:idx], demuxkey[idx+1:]
+	}
+	methodname := strings.TrimSuffix(demuxkey, "()")
+	meth, ok := methodByName(ptrval.Type(), methodname)
+	if !ok {
+		return p.errorf(ErrTagReference, nil, "Method '%v()' for '%v' not found. Referenced from a `demux` tag.", methodname, ptrval.Type())
+	}
+	if mt := meth.Type; mt.NumIn() != 3 || mt.In(1) != parserType || mt.In(2).Kind() != reflect.Uint || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.String {
+		return p.errorf(ErrTagReference, nil, "Invalid signature of method '%v' on '%v'. Referenced from a `demux` tag in '%v %v'. Expected func(*bingo.Parser, uint) string.", methodname, ptrval.Type(), fieldtyp.Name, fieldtyp.Type)
+	}
+
+	lenkey := getTag(fieldtyp, "len")
+	if len(lenkey) == 0 {
+		return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Fields tagged with `demux` require a `len` tag.", fieldtyp.Name, fieldtyp.Type)
+	}
+	count, err := p.parseLengthTag("len", lenkey, fieldtyp, ptrval, -1)
+	if err != nil {
+		return err
+	}
+
+	val := ptrval.Elem()
+	for i := uint64(0); i < count; i++ {
+		raw, err := p.readStorage("demux", storage, fieldtyp, ptrval)
+		if err != nil {
+			return err
+		}
+		var kind uint
+		if raw.CanInt() {
+			kind = uint(raw.Int())
+		} else {
+			kind = uint(raw.Uint())
+		}
+
+		results, err := p.callMethod(meth, []reflect.Value{ptrval, reflect.ValueOf(p), reflect.ValueOf(kind)})
+		if err != nil {
+			return err
+		}
+		name := results[0].String()
+		targettyp, ok := ptrval.Elem().Type().FieldByName(name)
+		if !ok || targettyp.Type.Kind() != reflect.Slice || getTag(targettyp, "demux") != "-" {
+			return p.errorf(nil, nil, "Error routing record %v of type %v in '%v'. '%v' is not a slice field tagged with `demux:\"-\"`.", i, kind, ptrval.Type(), name)
+		}
+		target := val.FieldByIndex(targettyp.Index)
+
+		elem := reflect.New(target.Type().Elem()).Elem()
+		if err := p.readElem(elem, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		target.Set(reflect.Append(target, elem))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+)
+
+type demuxComment struct {
+	Length uint8
+	Text   string `len:"Length"`
+}
+
+type demuxImage struct {
+	Width, Height uint16
+}
+
+type demuxFile struct {
+	Count    uint8
+	_        struct{}       `len:"Count" demux:"Route()"`
+	Comments []demuxComment `demux:"-"`
+	Images   []demuxImage   `demux:"-"`
+	Trailer  uint8
+}
+
+func (f *demuxFile) Route(p *Parser, kind uint) string {
+	switch kind {
+	case 1:
+		return "Comments"
+	case 2:
+		return "Images"
+	}
+	return ""
+}
+
+func TestDemux(t *testing.T) {
+	data := []byte{3,
+		1, 2, 'h', 'i',
+		2, 0x10, 0, 0x20, 0,
+		1, 1, '!',
+		0xFF}
+	s := demuxFile{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Comments) != 2 || s.Comments[0].Text != "hi" || s.Comments[1].Text != "!" {
+		t.Error("Error routing comments:", s.Comments)
+	}
+	if len(s.Images) != 1 || s.Images[0] != (demuxImage{16, 32}) {
+		t.Error("Error routing images:", s.Images)
+	}
+	if s.Trailer != 0xFF {
+		t.Error("Error parsing field after records:", s.Trailer)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestDemuxUnknownType(t *testing.T) {
+	s := demuxFile{}
+	p := newParserData([]byte{1, 7, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error routing record 0 of type 7 in '*bingo.demuxFile'. '' is not a slice field tagged with `demux:\"-\"`." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type voidDemux struct {
+	Count    uint8
+	_        struct{}       `len:"Count" demux:"Route()"`
+	Comments []demuxComment `demux:"-"`
+}
+
+func (v *voidDemux) Route(p *Parser, kind uint) {}
+
+func TestDemuxWrongSignature(t *testing.T) {
+	var s voidDemux
+	err := newParserData([]byte{1, 1, 0}).EmitReadStruct(&s)
+	if !errors.Is(err, ErrTagReference) || err.Error() != "Invalid signature of method 'Route' on '*bingo.voidDemux'. Referenced from a `demux` tag in '_ struct {}'. Expected func(*bingo.Parser, uint) string." {
+		t.Error("Incorrect error:", err)
+	}
+}
@@ -0,0 +1,47 @@
+package bingo
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"reflect"
+)
+
+// Moves past a field tagged with `discard`, as in `discard:"true"`, without
+// storing it. The number of bytes comes from the field's `size`, `len` or
+// `lenprefix` tag, or from its type if it has a fixed size:
+//
+//	Thumbnail []byte `len:"ThumbSize" discard:"true"`
+//
+// Readers that implement io.Seeker are seeked past the data instead of reading
+// it, as with EmitSkipNBytes. The field itself is left untouched.
+func (p *Parser) discardField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	var nbytes uint64
+	if sizekey := getTag(fieldtyp, "size"); len(sizekey) > 0 {
+		n, err := p.parseLengthTag("size", sizekey, fieldtyp, ptrval, -1)
+		if err != nil {
+			return err
+		}
+		nbytes = n
+	} else if length, ok, err := p.fieldLength(fieldtyp, ptrval); err != nil {
+		return err
+	} else if ok {
+		elemsize := 1
+		if fieldtyp.Type.Kind() == reflect.Slice {
+			elemsize = binary.Size(reflect.Zero(fieldtyp.Type.Elem()).Interface())
+		}
+		if elemsize < 0 {
+			return p.errorf(nil, nil, "Unable to discard '%v %v'. Elements have no fixed size; use a `size` tag.", fieldtyp.Name, fieldtyp.Type)
+		}
+		hi, lo := bits.Mul64(length, uint64(elemsize))
+		if hi != 0 {
+			return p.errorf(ErrInvalidLength, nil, "Unable to discard '%v %v'. Length %v is too large.", fieldtyp.Name, fieldtyp.Type, length)
+		}
+		nbytes = lo
+	} else if size := binary.Size(fieldval.Interface()); size >= 0 {
+		nbytes = uint64(size)
+	} else {
+		return p.errorf(nil, nil, "Unable to discard '%v %v'. Its size isn't known; use a `size` tag.", fieldtyp.Name, fieldtyp.Type)
+	}
+
+	return p.skipBytes(nbytes)
+}
@@ -0,0 +1,117 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type discardStruct struct {
+	ThumbSize uint8
+	Thumbnail []byte    `len:"ThumbSize" discard:"true"`
+	Reserved  [3]uint16 `discard:"true"`
+	Count     uint8
+	Entries   []uint16 `len:"Count" discard:"true"`
+	Next      uint8
+}
+
+var discardData = []byte{3, 1, 2, 3,
+	0, 0, 0, 0, 0, 0,
+	2, 0, 0, 0, 0,
+	0xAB}
+
+func TestDiscard(t *testing.T) {
+	s := discardStruct{}
+	p := newParserData(discardData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Thumbnail != nil || s.Entries != nil {
+		t.Error("Discarded fields were stored:", s)
+	}
+	if s.Next != 0xAB {
+		t.Error("Error parsing field after discarded ones:", s.Next)
+	}
+	if p.offset != uint64(len(discardData)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestDiscardNotSeekable(t *testing.T) {
+	s := discardStruct{}
+	p := NewParser(io.MultiReader(bytes.NewReader(discardData)), LittleEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Next != 0xAB {
+		t.Error("Error parsing field after discarded ones:", s.Next)
+	}
+	if p.offset != uint64(len(discardData)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+// Counts the bytes read through it
+type countingReader struct {
+	io.ReadSeeker
+	n     int
+	seeks int
+}
+
+func (r *countingReader) Seek(offset int64, whence int) (int64, error) {
+	r.seeks++
+	return r.ReadSeeker.Seek(offset, whence)
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(b)
+	r.n += n
+	return n, err
+}
+
+func TestSkipSeeks(t *testing.T) {
+	r := &countingReader{ReadSeeker: bytes.NewReader(make([]byte, 1<<20+1))}
+	p := NewParser(r, LittleEndian, Default)
+
+	if err := p.EmitSkipNBytes(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if r.n != 0 || p.Offset() != 1<<20 {
+		t.Errorf("Expected a seek. Read %v bytes, offset %v", r.n, p.Offset())
+	}
+	if err := p.EmitSkipNBytes(2); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+func TestSkipShort(t *testing.T) {
+	r := &countingReader{ReadSeeker: bytes.NewReader(make([]byte, 64))}
+	p := NewParser(r, LittleEndian, Default)
+
+	for i := 0; i < 8; i++ {
+		if err := p.EmitSkipNBytes(8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.seeks != 0 || p.Offset() != 64 {
+		t.Errorf("Expected short skips to read through. Seeked %v times, offset %v", r.seeks, p.Offset())
+	}
+}
+
+func TestSkipNotSeekable(t *testing.T) {
+	data := make([]byte, 1<<20)
+	allocs := testing.AllocsPerRun(10, func() {
+		p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+		if err := p.EmitSkipNBytes(len(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 10 {
+		t.Errorf("Too many allocations: %v", allocs)
+	}
+}
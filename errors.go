@@ -0,0 +1,111 @@
+package bingo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Classes of errors, for use with errors.Is on the errors returned by the
+// parser:
+//
+//	if errors.Is(err, bingo.ErrUnexpectedEOF) {
+//		// truncated file
+//	}
+var (
+	// The field's type can't be parsed, or not with the tags it has
+	ErrUnsupportedType = errors.New("unsupported type")
+	// A tag refers to a field or method that doesn't exist or can't be used
+	ErrTagReference = errors.New("invalid tag reference")
+	// A tag has a malformed value or is combined with conflicting tags
+	ErrInvalidTag = errors.New("invalid tag")
+	// The data ended before the parse was complete
+	ErrUnexpectedEOF = errors.New("unexpected EOF")
+	// A hook such as an `after` method returned an error
+	ErrVerifyFailed = errors.New("verification failed")
+	// A `magic`, `assert` or range check didn't hold, or padding or a byte
+	// order mark had unexpected contents
+	ErrAssertionFailed = errors.New("assertion failed")
+	// A size-limited field didn't consume exactly its size
+	ErrSizeMismatch = errors.New("size mismatch")
+	// A length, size or count taken from the data is negative or larger
+	// than the data that's left
+	ErrInvalidLength = errors.New("invalid length")
+	// A method called from a tag or a hook interface panicked
+	ErrHookPanicked = errors.New("hook panicked")
+)
+
+// ParseError describes where and why parsing failed.
+type ParseError struct {
+	// Offset of the parser when the error occurred
+	Offset uint64
+	// Path to the field being read, as in "Header.Entries[1].Name"
+	FieldPath string
+	// Type of the field being read, if any
+	Type reflect.Type
+	// Error that caused this one, such as io.EOF from the reader or the
+	// error returned by a hook, if any
+	Err error
+	// Input around Offset, starting at ContextOffset, if the parser was
+	// asked for it with SetErrorContext
+	Context       []byte
+	ContextOffset uint64
+
+	kind error
+	text string
+}
+
+// Creates a ParseError located at the field the parser is currently reading.
+func (p *Parser) parseError(kind, cause error, msg string) *ParseError {
+	perr := &ParseError{Offset: p.offset, FieldPath: p.fieldPath(), Err: cause, kind: kind, text: msg}
+	if n := len(p.stack); n > 0 {
+		perr.Type = p.stack[n-1].fieldType
+	}
+	p.attachContext(perr)
+	return perr
+}
+
+func (err *ParseError) Error() string {
+	if len(err.Context) == 0 {
+		return err.text
+	}
+	return err.text + err.hexdump()
+}
+
+// Unwrap returns the error that caused the parse to fail, or nil.
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether the error belongs to the class of one of the Err
+// sentinels.
+func (err *ParseError) Is(target error) bool {
+	if target == ErrUnexpectedEOF {
+		return errors.Is(err.Err, io.EOF) || errors.Is(err.Err, io.ErrUnexpectedEOF)
+	}
+	return err.kind != nil && target == err.kind
+}
+
+// Describes the field being read as a path from the outermost struct.
+func (p *Parser) fieldPath() string {
+	return formatFieldPath(p.stack)
+}
+
+func formatFieldPath(stack []frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		if f.index >= 0 {
+			fmt.Fprintf(&b, "[%d]", f.index)
+		}
+		if len(f.field) == 0 {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(f.field)
+	}
+	return b.String()
+}
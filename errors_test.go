@@ -0,0 +1,84 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestErrorClasses(t *testing.T) {
+	tests := []struct {
+		data   interface{}
+		target error
+	}{
+		{&struct{ Ch chan int }{}, ErrUnsupportedType},
+		{&struct {
+			Data []byte `len:"Missing"`
+		}{}, ErrTagReference},
+		{&struct {
+			Data []byte `len:"1" size:"1"`
+		}{}, ErrInvalidTag},
+		{&struct {
+			Value uint32 `magic:"1"`
+		}{}, ErrAssertionFailed},
+		{&struct {
+			Data [64]byte
+		}{}, ErrUnexpectedEOF},
+		{&FailingVerifier{}, ErrVerifyFailed},
+	}
+	for _, test := range tests {
+		p := newParser()
+
+		err := p.EmitReadStruct(test.data)
+		if !errors.Is(err, test.target) {
+			t.Errorf("Expected %v, got %v", test.target, err)
+		}
+		for _, other := range []error{ErrUnsupportedType, ErrTagReference, ErrInvalidTag, ErrAssertionFailed, ErrUnexpectedEOF, ErrVerifyFailed} {
+			if other != test.target && errors.Is(err, other) {
+				t.Errorf("Error %v also matches %v", err, other)
+			}
+		}
+	}
+}
+
+func TestErrorWrapsHookError(t *testing.T) {
+	s := computeError{}
+	p := newParserData([]byte{1})
+
+	err := p.EmitReadStruct(&s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err == nil || perr.Err.Error() != "bad version" {
+		t.Error("Expected the hook's error to be wrapped:", err)
+	}
+}
+
+func TestUnexpectedEOFMessage(t *testing.T) {
+	s := struct {
+		Version uint8
+		Length  uint32
+	}{}
+	p := newParserData([]byte{1, 0x10, 0x20})
+
+	err := p.EmitReadStruct(&s)
+	if perr, ok := err.(*ParseError); !ok || perr.Error() != "Unexpected end of data while reading 'Length uint32' of struct { Version uint8; Length uint32 } at offset 1: expected 4 bytes, got 2." {
+		t.Error("Incorrect error:", err)
+	}
+	if !errors.Is(err, ErrUnexpectedEOF) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected the error to match ErrUnexpectedEOF:", err)
+	}
+	if s.Version != 1 {
+		t.Error("Expected fields before the failure to be kept:", s.Version)
+	}
+}
+
+func TestUnexpectedEOFString(t *testing.T) {
+	s := struct {
+		Name string `len:"4"`
+	}{}
+	p := newParserData([]byte{'a', 'b'})
+
+	err := p.EmitReadStruct(&s)
+	if perr, ok := err.(*ParseError); !ok || perr.Error() != "Unexpected end of data while reading 'Name string' of struct { Name string \"len:\\\"4\\\"\" } at offset 0: expected 4 bytes, got 2." {
+		t.Error("Incorrect error:", err)
+	}
+}
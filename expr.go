@@ -0,0 +1,323 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Expressions are small Go-like formulas over the fields of the struct being
+// parsed, as in `if:"Version>=2 && Flags&0x4!=0"`. All values are int64;
+// comparisons and logical operators yield 1 or 0, and any non-zero value
+// counts as true. Operators and their precedence follow Go:
+//
+//	||
+//	&&
+//	==  !=  <  <=  >  >=
+//	+  -  |  ^
+//	*  /  %  <<  >>  &
+//	unary !  -  ^
+//
+// Operands are integer literals, field names and methods. Bool fields count as
+// 1 or 0, fields of nested structs are reached with dots, as in
+// Header.Version, and a "^." prefix refers to the enclosing struct. A method
+// is called with the *Parser like the ones referenced from `len` tags and may
+// return a bool or an integer; it can be written either as Name() or, when no
+// field has that name, as plain Name.
+type expr func(p *Parser, ptrval reflect.Value) (int64, error)
+
+var exprCache sync.Map // map[string]expr
+
+// Evaluates the expression in the given tag against the struct ptrval points
+// to. Compiled expressions are cached by tag and source text.
+func (p *Parser) evalExpr(tag, src string, ptrval reflect.Value) (int64, error) {
+	key := tag + ":" + src
+	e, ok := exprCache.Load(key)
+	if !ok {
+		compiled, err := compileExpr(tag, src)
+		if err != nil {
+			return 0, p.errorf(ErrInvalidTag, nil, "Invalid expression in `%v` tag: %v. %v.", tag, src, err)
+		}
+		e, _ = exprCache.LoadOrStore(key, compiled)
+	}
+	return e.(expr)(p, ptrval)
+}
+
+type exprParser struct {
+	tag    string
+	tokens []string
+	pos    int
+}
+
+func compileExpr(tag, src string) (expr, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	ep := exprParser{tag: tag, tokens: tokens}
+	e, err := ep.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if ep.pos < len(ep.tokens) {
+		return nil, fmt.Errorf("Unexpected '%v'", ep.tokens[ep.pos])
+	}
+	return e, nil
+}
+
+var exprOperators = []string{
+	"||", "&&", "==", "!=", "<=", ">=", "<<", ">>",
+	"<", ">", "+", "-", "*", "/", "%", "&", "|", "^", "!", "(", ")",
+}
+
+func tokenizeExpr(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case isIdentByte(c) || strings.HasPrefix(src[i:], "^."):
+			j := i
+			for strings.HasPrefix(src[j:], "^.") {
+				j += 2
+			}
+			for j < len(src) && (isIdentByte(src[j]) || src[j] == '.') {
+				j++
+			}
+			// method call
+			if strings.HasPrefix(src[j:], "()") {
+				j += 2
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, op)
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("Unexpected character '%c'", c)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+var exprPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4, "|": 4, "^": 4,
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5, "&": 5,
+}
+
+func (ep *exprParser) peek() string {
+	if ep.pos < len(ep.tokens) {
+		return ep.tokens[ep.pos]
+	}
+	return ""
+}
+
+// Precedence climbing over the binary operators
+func (ep *exprParser) parseBinary(minPrec int) (expr, error) {
+	lhs, err := ep.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ep.peek()
+		prec, ok := exprPrecedence[op]
+		if !ok || prec < minPrec {
+			return lhs, nil
+		}
+		ep.pos++
+		rhs, err := ep.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryExpr(ep.tag, op, lhs, rhs)
+	}
+}
+
+func (ep *exprParser) parseUnary() (expr, error) {
+	switch tok := ep.peek(); tok {
+	case "!", "-", "^":
+		ep.pos++
+		operand, err := ep.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Parser, ptrval reflect.Value) (int64, error) {
+			x, err := operand(p, ptrval)
+			switch tok {
+			case "!":
+				return boolInt(x == 0), err
+			case "-":
+				return -x, err
+			default:
+				return ^x, err
+			}
+		}, nil
+
+	case "(":
+		ep.pos++
+		e, err := ep.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if ep.peek() != ")" {
+			return nil, fmt.Errorf("Missing ')'")
+		}
+		ep.pos++
+		return e, nil
+
+	case "":
+		return nil, fmt.Errorf("Unexpected end of expression")
+	}
+
+	tok := ep.tokens[ep.pos]
+	ep.pos++
+	if '0' <= tok[0] && tok[0] <= '9' {
+		n, err := strconv.ParseUint(tok, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number '%v'", tok)
+		}
+		return func(*Parser, reflect.Value) (int64, error) { return int64(n), nil }, nil
+	}
+	if !isIdentByte(tok[0]) && tok[0] != '^' {
+		return nil, fmt.Errorf("Unexpected '%v'", tok)
+	}
+	tag := ep.tag
+	return func(p *Parser, ptrval reflect.Value) (int64, error) { return p.exprOperand(tag, tok, ptrval) }, nil
+}
+
+var exprBinaryOps = map[string]func(a, b int64) int64{
+	"==": func(a, b int64) int64 { return boolInt(a == b) },
+	"!=": func(a, b int64) int64 { return boolInt(a != b) },
+	"<":  func(a, b int64) int64 { return boolInt(a < b) },
+	"<=": func(a, b int64) int64 { return boolInt(a <= b) },
+	">":  func(a, b int64) int64 { return boolInt(a > b) },
+	">=": func(a, b int64) int64 { return boolInt(a >= b) },
+	"+":  func(a, b int64) int64 { return a + b },
+	"-":  func(a, b int64) int64 { return a - b },
+	"|":  func(a, b int64) int64 { return a | b },
+	"^":  func(a, b int64) int64 { return a ^ b },
+	"*":  func(a, b int64) int64 { return a * b },
+	"/":  func(a, b int64) int64 { return a / b },
+	"%":  func(a, b int64) int64 { return a % b },
+	"<<": func(a, b int64) int64 { return a << uint64(b) },
+	">>": func(a, b int64) int64 { return a >> uint64(b) },
+	"&":  func(a, b int64) int64 { return a & b },
+}
+
+func binaryExpr(tag, op string, lhs, rhs expr) expr {
+	if op == "||" || op == "&&" {
+		// The right-hand side is only evaluated if it decides the result
+		return func(p *Parser, v reflect.Value) (int64, error) {
+			a, err := lhs(p, v)
+			if err != nil || (a != 0) == (op == "||") {
+				return boolInt(a != 0), err
+			}
+			b, err := rhs(p, v)
+			return boolInt(b != 0), err
+		}
+	}
+
+	apply := exprBinaryOps[op]
+	return func(p *Parser, v reflect.Value) (int64, error) {
+		a, err := lhs(p, v)
+		if err != nil {
+			return 0, err
+		}
+		b, err := rhs(p, v)
+		if err != nil {
+			return 0, err
+		}
+		if b == 0 && (op == "/" || op == "%") {
+			return 0, p.errorf(nil, nil, "Division by zero in `%v` tag expression on '%v'.", tag, v.Type())
+		}
+		return apply(a, b), nil
+	}
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Resolves a field or method name used in an expression.
+func (p *Parser) exprOperand(tag, name string, ptrval reflect.Value) (int64, error) {
+	ptrval, name, err := p.resolveAncestor(tag, name, ptrval)
+	if err != nil {
+		return 0, err
+	}
+	methodname := strings.TrimSuffix(name, "()")
+	if methodname == name {
+		if fieldval := fieldByPath(ptrval.Elem(), name); fieldval.IsValid() {
+			return p.exprValue(tag, name, fieldval, ptrval)
+		}
+	}
+
+	article := "a"
+	if strings.ContainsRune("aeiou", rune(tag[0])) {
+		article = "an"
+	}
+	meth, ok := methodByName(ptrval.Type(), methodname)
+	if !ok {
+		return 0, p.errorf(ErrTagReference, nil, "Field or method '%v' for '%v' not found. Referenced from %v `%v` tag.", methodname, ptrval.Type(), article, tag)
+	}
+	if mt := meth.Type; mt.NumIn() != 2 || mt.In(1) != parserType || mt.NumOut() != 1 {
+		return 0, p.errorf(ErrTagReference, nil, "Invalid signature of method '%v' on '%v'. Referenced from %v `%v` tag. Expected func(*bingo.Parser) returning a single value.", methodname, ptrval.Type(), article, tag)
+	}
+	results, err := p.callMethod(meth, []reflect.Value{ptrval, reflect.ValueOf(p)})
+	if err != nil {
+		return 0, err
+	}
+	return p.exprValue(tag, name, results[0], ptrval)
+}
+
+func (p *Parser) exprValue(tag, name string, val reflect.Value, ptrval reflect.Value) (int64, error) {
+	switch val.Kind() {
+	case reflect.Bool:
+		return boolInt(val.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(val.Uint()), nil
+	}
+	article := "a"
+	if strings.ContainsRune("aeiou", rune(tag[0])) {
+		article = "an"
+	}
+	return 0, p.errorf(ErrTagReference, nil, "Error trying to use '%v' of type %v in an expression. Referenced from %v `%v` tag in '%v'.", name, val.Type(), article, tag, ptrval.Type())
+}
+
+// Looks up a field by a dot-separated path through nested structs.
+func fieldByPath(val reflect.Value, path string) reflect.Value {
+	for more := true; more; {
+		var name string
+		name, path, more = strings.Cut(path, ".")
+		if val.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		val = val.FieldByName(name)
+		if !val.IsValid() {
+			return val
+		}
+	}
+	return val
+}
@@ -0,0 +1,191 @@
+package bingo
+
+import (
+	"testing"
+)
+
+type VersionedHeader struct {
+	Version uint8
+	Flags   uint8
+	Extra   uint16 `if:"Version>=2 && Flags&0x4!=0"`
+	Legacy  uint8  `if:"!(Version >= 2) || Wide"`
+}
+
+func (h *VersionedHeader) Wide(p *Parser) bool {
+	return h.Flags&0x1 != 0
+}
+
+func TestIfExpression(t *testing.T) {
+	data := []byte{2, 5, 0xAA, 0xBB, 0xCC}
+	s := VersionedHeader{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Extra == 0xBBAA && s.Legacy == 0xCC) {
+		t.Error("Error evaluating if expressions:", s)
+	}
+	if p.offset != 5 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestIfExpressionFalse(t *testing.T) {
+	data := []byte{2, 0, 0xAA}
+	s := VersionedHeader{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Extra == 0 && s.Legacy == 0) {
+		t.Error("Error evaluating if expressions:", s)
+	}
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestIfExpressionNested(t *testing.T) {
+	data := []byte{3, 0, 1, 2}
+	s := struct {
+		Header struct {
+			Version uint8
+			Flags   uint8
+		}
+		Body uint16 `if:"Header.Version * 2 - 1 == 5"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Body != 0x201 {
+		t.Error("Error evaluating expression with nested field:", s.Body)
+	}
+}
+
+func TestIfExpressionSyntaxError(t *testing.T) {
+	s := struct {
+		Version uint8
+		Extra   uint8 `if:"Version >= (2"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid expression in `if` tag: Version >= (2. Missing ')'." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestIfExpressionUnknownField(t *testing.T) {
+	s := struct {
+		Version uint8
+		Extra   uint8 `if:"Revision > 1"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Field or method 'Revision' for '*struct { Version uint8; Extra uint8 \"if:\\\"Revision > 1\\\"\" }' not found. Referenced from an `if` tag." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestIfDefault(t *testing.T) {
+	data := []byte{1, 7}
+	s := struct {
+		Version  uint8
+		Extra    uint16  `if:"Version >= 2" default:"0xFFFF"`
+		Scale    int8    `if:"Version >= 2" default:"-3"`
+		Ratio    float32 `if:"Version >= 2" default:"1.5"`
+		Enabled  bool    `if:"Version >= 2" default:"true"`
+		Name     string  `if:"Version >= 2" default:"none"`
+		Tag      [4]byte `if:"Version >= 2" default:"'NONE'"`
+		Trailing uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Extra == 0xFFFF && s.Scale == -3 && s.Ratio == 1.5 && s.Enabled && s.Name == "none" && string(s.Tag[:]) == "NONE") {
+		t.Error("Error applying defaults:", s)
+	}
+	if s.Trailing != 7 {
+		t.Error("Error parsing field after defaults:", s.Trailing)
+	}
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestIfDefaultInvalid(t *testing.T) {
+	s := struct {
+		Version uint8
+		Extra   uint8 `if:"Version >= 2" default:"256"`
+	}{}
+	p := newParserData([]byte{1})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid value for `default` tag on 'Extra uint8': 256." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+type badPredicate struct {
+	Flags uint8
+	Extra uint8 `if:"HasExtra"`
+}
+
+func (b *badPredicate) HasExtra(p *Parser) uint8 {
+	return 1
+}
+
+func TestIfMethodSignature(t *testing.T) {
+	s := badPredicate{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid signature of method 'HasExtra' on '*bingo.badPredicate'. Referenced from an `if` tag in 'Extra uint8'. Expected func(*bingo.Parser) bool." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+type badExprMethod struct {
+	Flags uint8
+	Tail  uint8 `if:"Flags > 0 && HasTail"`
+}
+
+func (b *badExprMethod) HasTail() bool {
+	return true
+}
+
+func TestIfExpressionMethodSignature(t *testing.T) {
+	s := badExprMethod{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid signature of method 'HasTail' on '*bingo.badExprMethod'. Referenced from an `if` tag. Expected func(*bingo.Parser) returning a single value." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
@@ -0,0 +1,43 @@
+package bingo
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Calls the method named by a slice field's `factory` tag to get the value
+// its element at index is parsed into. This lets slices of interfaces hold
+// elements of different concrete types:
+//
+//	Blocks []Block `len:"Count" factory:"NewBlock"`
+//
+//	func (f *File) NewBlock(p *bingo.Parser, index int) Block
+//
+// The method must return a non-nil pointer to a struct that can be stored in
+// the slice. It works for slices with a `len`, `lenprefix` or `size` tag.
+func (p *Parser) callFactory(factorykey string, fieldtyp reflect.StructField, ptrval reflect.Value, index int) (reflect.Value, error) {
+	methodname := strings.TrimSuffix(factorykey, "()")
+	meth, ok := methodByName(ptrval.Type(), methodname)
+	if !ok {
+		return reflect.Value{}, p.errorf(ErrTagReference, nil, "Method '%v()' for '%v' not found. Referenced from a `factory` tag.", methodname, ptrval.Type())
+	}
+	if mt := meth.Type; mt.NumIn() != 3 || mt.In(1) != parserType || mt.In(2).Kind() != reflect.Int || mt.NumOut() != 1 {
+		return reflect.Value{}, p.errorf(ErrTagReference, nil, "Invalid signature of method '%v' on '%v'. Referenced from a `factory` tag in '%v %v'. Expected func(*bingo.Parser, int) with one result.", methodname, ptrval.Type(), fieldtyp.Name, fieldtyp.Type)
+	}
+
+	results, err := p.callMethod(meth, []reflect.Value{ptrval, reflect.ValueOf(p), reflect.ValueOf(index)})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	result := results[0]
+	if result.Kind() == reflect.Interface {
+		result = result.Elem()
+	}
+	if !result.IsValid() || result.Kind() != reflect.Ptr || result.IsNil() || result.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, p.errorf(nil, nil, "Error reading element %v of '%v %v'. Method '%v' must return a non-nil pointer to a struct.", index, fieldtyp.Name, fieldtyp.Type, methodname)
+	}
+	if !result.Type().AssignableTo(fieldtyp.Type.Elem()) {
+		return reflect.Value{}, p.errorf(nil, nil, "Error reading element %v of '%v %v'. Method '%v' returned %v.", index, fieldtyp.Name, fieldtyp.Type, methodname, result.Type())
+	}
+	return result, nil
+}
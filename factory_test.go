@@ -0,0 +1,112 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+)
+
+type factoryBlock interface {
+	Kind() uint8
+}
+
+type factoryText struct {
+	Tag  uint8
+	Text [2]byte
+}
+
+func (b *factoryText) Kind() uint8 { return b.Tag }
+
+type factoryNumber struct {
+	Tag   uint8
+	Value uint16
+}
+
+func (b *factoryNumber) Kind() uint8 { return b.Tag }
+
+type factoryFile struct {
+	Kinds  [3]uint8
+	Blocks []factoryBlock `len:"3" factory:"NewBlock"`
+	Size   uint8
+	Sized  []factoryBlock `size:"Size" factory:"NewBlock"`
+}
+
+func (f *factoryFile) NewBlock(p *Parser, index int) factoryBlock {
+	if f.Kinds[index%3] == 1 {
+		return &factoryText{}
+	}
+	return &factoryNumber{}
+}
+
+func TestFactory(t *testing.T) {
+	data := []byte{1, 2, 1,
+		1, 'h', 'i',
+		2, 0x34, 0x12,
+		1, 'y', 'o',
+		6,
+		1, 'a', 'b',
+		2, 1, 0}
+	s := factoryFile{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Blocks) != 3 {
+		t.Fatal("Invalid number of blocks:", len(s.Blocks))
+	}
+	if b, ok := s.Blocks[0].(*factoryText); !ok || string(b.Text[:]) != "hi" {
+		t.Error("Error parsing first block:", s.Blocks[0])
+	}
+	if b, ok := s.Blocks[1].(*factoryNumber); !ok || b.Value != 0x1234 {
+		t.Error("Error parsing second block:", s.Blocks[1])
+	}
+	if b, ok := s.Blocks[2].(*factoryText); !ok || string(b.Text[:]) != "yo" {
+		t.Error("Error parsing third block:", s.Blocks[2])
+	}
+	if len(s.Sized) != 2 || s.Sized[0].Kind() != 1 || s.Sized[1].Kind() != 2 {
+		t.Error("Error parsing sized blocks:", s.Sized)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type factoryNil struct {
+	Blocks []factoryBlock `len:"1" factory:"NewBlock"`
+}
+
+func (f *factoryNil) NewBlock(p *Parser, index int) factoryBlock {
+	return nil
+}
+
+func TestFactoryNil(t *testing.T) {
+	s := factoryNil{}
+	p := newParserData([]byte{0, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading element 0 of 'Blocks []bingo.factoryBlock'. Method 'NewBlock' must return a non-nil pointer to a struct." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 0 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type voidFactory struct {
+	Blocks []factoryBlock `len:"1" factory:"NewBlock"`
+}
+
+func (v *voidFactory) NewBlock(p *Parser, index int) {}
+
+func TestFactoryWrongSignature(t *testing.T) {
+	var s voidFactory
+	err := newParserData([]byte{0, 0, 0}).EmitReadStruct(&s)
+	if !errors.Is(err, ErrTagReference) || err.Error() != "Invalid signature of method 'NewBlock' on '*bingo.voidFactory'. Referenced from a `factory` tag in 'Blocks []bingo.factoryBlock'. Expected func(*bingo.Parser, int) with one result." {
+		t.Error("Incorrect error:", err)
+	}
+}
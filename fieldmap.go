@@ -0,0 +1,75 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A FieldSpan is the range of the input a field was read from.
+type FieldSpan struct {
+	Path   string // as in "Header.Entries[1].Name", rooted at the parsed type
+	Type   reflect.Type
+	Offset uint64
+	Size   uint64 // not counting padding
+}
+
+// SetFieldMap makes the parser record where each field it reads starts and
+// ends, as returned by FieldMap.
+func (p *Parser) SetFieldMap(on bool) {
+	p.recordFields = on
+}
+
+// FieldMap returns the fields read by the last call to EmitReadStruct, in
+// the order they appear in the input. A field holding a struct comes before
+// the fields of its own.
+func (p *Parser) FieldMap() []FieldSpan {
+	spans := append([]FieldSpan(nil), p.fieldMap...)
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Offset != spans[j].Offset {
+			return spans[i].Offset < spans[j].Offset
+		}
+		return spans[i].Size > spans[j].Size
+	})
+	return spans
+}
+
+// Records the field the parser is at, which started at offset and ended
+// where the parser is now.
+func (p *Parser) recordField(offset uint64) {
+	top := p.stack[len(p.stack)-1]
+	p.fieldMap = append(p.fieldMap, FieldSpan{p.rootedFieldPath(), top.fieldType, offset, p.offset - offset})
+}
+
+// Returns the path of the field the parser is at, prefixed with the name of
+// the type passed to EmitReadStruct.
+func (p *Parser) rootedFieldPath() string {
+	path := formatFieldPath(p.stack)
+	if name := p.stack[0].ptrval.Elem().Type().Name(); len(name) > 0 {
+		path = name + "." + path
+	}
+	return path
+}
+
+// AnnotatedHexdump renders data, the input of a parse, as rows of 16 bytes,
+// each followed by the fields from fields that start in it:
+//
+//	00000000  42 47 02 01 00 61 62 63  64 02 00 65 66 67 68 00  |BG...abcd..efgh.|
+//	          0x0 +2  TraceHeader.Magic [2]uint8
+//	          0x2 +1  TraceHeader.Count uint8
+//
+// Each field is shown with its offset and its size in bytes.
+func AnnotatedHexdump(data []byte, fields []FieldSpan) string {
+	var b strings.Builder
+	next := 0
+	for row := uint64(0); row < uint64(len(data)); row += hexdumpRow {
+		writeHexRow(&b, row, data, 0)
+		b.WriteByte('\n')
+		for ; next < len(fields) && fields[next].Offset < row+hexdumpRow; next++ {
+			f := fields[next]
+			fmt.Fprintf(&b, "%10s0x%X +%v  %v %v\n", "", f.Offset, f.Size, f.Path, f.Type)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package bingo
+
+import (
+	"testing"
+)
+
+func TestFieldMap(t *testing.T) {
+	s := struct {
+		Header struct {
+			Version uint8
+			Flags   uint8 `pad:"2"`
+		}
+		Entries []traceEntry `len:"1"`
+	}{}
+	p := newParserData([]byte{1, 2, 0, 3, 4, 'a', 'b', 'c', 'd'})
+	p.SetFieldMap(true)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		path         string
+		offset, size uint64
+	}{
+		{"Header", 0, 3},
+		{"Header.Version", 0, 1},
+		{"Header.Flags", 1, 1},
+		{"Entries", 3, 6},
+	}
+	fields := p.FieldMap()
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %v fields. Got %v", len(expected), fields)
+	}
+	for i, f := range fields {
+		if e := expected[i]; f.Path != e.path || f.Offset != e.offset || f.Size != e.size {
+			t.Errorf("Incorrect span %v: %+v", i, f)
+		}
+	}
+}
+
+func TestFieldMapDisabled(t *testing.T) {
+	s := traceEntry{}
+	p := newParserData([]byte{1, 0, 'a', 'b', 'c', 'd'})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if fields := p.FieldMap(); len(fields) != 0 {
+		t.Errorf("Expected no fields. Got %v", fields)
+	}
+}
+
+func TestAnnotatedHexdump(t *testing.T) {
+	s := TraceHeader{}
+	data := append([]byte{'B', 'G', 1, 1, 0, 'a', 'b', 'c', 'd'}, make([]byte, 20)...)
+	p := newParserData(data)
+	p.SetFieldMap(true)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	expected := `00000000  42 47 01 01 00 61 62 63  64 00 00 00 00 00 00 00  |BG...abcd.......|
+          0x0 +2  TraceHeader.Magic [2]uint8
+          0x2 +1  TraceHeader.Count uint8
+          0x3 +6  TraceHeader.Entries []bingo.traceEntry
+          0x9 +20  TraceHeader.Data []uint8
+00000010  00 00 00 00 00 00 00 00  00 00 00 00 00           |.............|
+`
+	if dump := AnnotatedHexdump(data, p.FieldMap()); dump != expected {
+		t.Errorf("Incorrect hexdump:\n%v", dump)
+	}
+}
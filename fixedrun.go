@@ -0,0 +1,140 @@
+package bingo
+
+import (
+	"io"
+	"math"
+	"reflect"
+)
+
+// Returns the size of a field that is read as is, with no tags or special
+// handling, from a fixed number of bytes, or 0 if it isn't one. Runs of such
+// fields are read from the stream in one go.
+func plainFixedSize(f *fieldPlan) int {
+	if len(f.tags) > 0 || f.skip || len(f.tagProblem) > 0 || len(f.PkgPath) > 0 {
+		return 0
+	}
+	if !isPlainFixedType(f.Type) {
+		return 0
+	}
+	return int(f.Type.Size())
+}
+
+func isPlainFixedType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Int64:
+		return typ != durationType
+	case reflect.Array:
+		return typ != uuidType && isPlainFixedType(typ.Elem())
+	}
+	return false
+}
+
+// Works out the runs of plain fixed-size fields of a plan, recording the
+// length and the size of the run starting at each field.
+func (plan *structPlan) findFixedRuns() {
+	for i := len(plan.fields) - 1; i >= 0; i-- {
+		f := &plan.fields[i]
+		if f.size = plainFixedSize(f); f.size == 0 {
+			continue
+		}
+		if len(plan.captures[f.Name]) > 0 {
+			// captured fields are read on their own
+			f.size = 0
+			continue
+		}
+		f.run, f.runSize, f.packed = 1, f.size, 1
+		if i+1 < len(plan.fields) {
+			if next := &plan.fields[i+1]; next.run > 0 {
+				f.run, f.runSize = next.run+1, next.runSize+f.size
+				if next.Offset == f.Offset+uintptr(f.size) {
+					f.packed = next.packed + 1
+				}
+			}
+		}
+	}
+}
+
+// Reads the run of plain fixed-size fields starting at field first of the
+// struct val with a single read, then decodes the fields from the bytes
+// read.
+func (p *Parser) readFixedRun(val reflect.Value, plan *structPlan, first int) error {
+	p.alignBits()
+	top := len(p.stack) - 1
+	run := plan.fields[first : first+plan.fields[first].run]
+	buf := p.scratchBuf(plan.fields[first].runSize)
+	got, err := io.ReadFull(p.r, buf)
+	// All there, and possibly in the right shape to be copied as is
+	copied := got == len(buf) && p.copyNativeRun(val, plan, first, buf)
+
+	for i := range run {
+		f := &run[i]
+		fieldval := val.Field(first + i)
+		p.stack[top].field, p.stack[top].fieldType = f.Name, f.Type
+		if got < f.size {
+			if got == 0 {
+				err = io.EOF
+			} else if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return p.shortReadError(err, int64(f.size), int64(got))
+		}
+
+		offset := p.offset
+		if !copied {
+			p.decodeFixed(buf[:f.size], fieldval)
+		}
+		buf, got = buf[f.size:], got-f.size
+		p.offset += uint64(f.size)
+
+		if p.trace != nil {
+			p.traceField(offset, fieldval, true)
+		}
+		p.slogField(offset, fieldval)
+		if p.recordFields {
+			p.recordField(offset)
+		}
+		p.lastStack, p.lastOffset = append(p.lastStack[:0], p.stack...), p.offset
+	}
+	return nil
+}
+
+// Decodes a value of a plain fixed-size type from buf, which holds exactly
+// its size in bytes.
+func (p *Parser) decodeFixed(buf []byte, val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Uint8:
+		val.SetUint(uint64(buf[0]))
+	case reflect.Uint16:
+		val.SetUint(uint64(p.byteOrder.Uint16(buf)))
+	case reflect.Uint32:
+		val.SetUint(uint64(p.byteOrder.Uint32(buf)))
+	case reflect.Uint64:
+		val.SetUint(p.byteOrder.Uint64(buf))
+	case reflect.Int8:
+		val.SetInt(int64(int8(buf[0])))
+	case reflect.Int16:
+		val.SetInt(int64(int16(p.byteOrder.Uint16(buf))))
+	case reflect.Int32:
+		val.SetInt(int64(int32(p.byteOrder.Uint32(buf))))
+	case reflect.Int64:
+		val.SetInt(int64(p.byteOrder.Uint64(buf)))
+	case reflect.Float32:
+		val.SetFloat(float64(math.Float32frombits(p.byteOrder.Uint32(buf))))
+	case reflect.Float64:
+		val.SetFloat(math.Float64frombits(p.byteOrder.Uint64(buf)))
+	case reflect.Array:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(val, reflect.ValueOf(buf))
+			return
+		}
+		if p.copyNative(val, buf) {
+			return
+		}
+		size := int(val.Type().Elem().Size())
+		for i := 0; i < val.Len(); i++ {
+			p.decodeFixed(buf[i*size:(i+1)*size], val.Index(i))
+		}
+	}
+}
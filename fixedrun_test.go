@@ -0,0 +1,83 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fixedRunStruct struct {
+	A   uint8
+	B   int16
+	C   [2]uint16
+	D   float32
+	Len uint8
+	E   []byte `len:"Len"`
+	F   int32
+	G   [3]byte
+}
+
+func TestFixedRun(t *testing.T) {
+	data := []byte{
+		1, 0xFE, 0xFF, 2, 0, 3, 0, 0, 0, 0x80, 0x3F, 2,
+		'h', 'i',
+		0xFF, 0xFF, 0xFF, 0xFF, 'a', 'b', 'c',
+	}
+	s := fixedRunStruct{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.A != 1 || s.B != -2 || s.C != [2]uint16{2, 3} || s.D != 1 || s.Len != 2 || string(s.E) != "hi" || s.F != -1 || string(s.G[:]) != "abc" {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+	if p.Offset() != uint64(len(data)) {
+		t.Errorf("Invalid offset: %v", p.Offset())
+	}
+}
+
+func TestFixedRunBigEndian(t *testing.T) {
+	s := struct {
+		A uint16
+		B uint32
+	}{}
+	p := NewParser(bytes.NewReader([]byte{1, 2, 3, 4, 5, 6}), BigEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.A != 0x0102 || s.B != 0x03040506 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+}
+
+func TestFixedRunTruncated(t *testing.T) {
+	s := fixedRunStruct{}
+	p := newParserData([]byte{1, 0xFE, 0xFF, 2, 0, 3})
+
+	err := p.EmitReadStruct(&s)
+	if !errors.Is(err, ErrUnexpectedEOF) || err.Error() != "Unexpected end of data while reading 'C [2]uint16' of bingo.fixedRunStruct at offset 3: expected 4 bytes, got 3." {
+		t.Fatal("Incorrect error:", err)
+	}
+	if s.A != 1 || s.B != -2 {
+		t.Errorf("Expected the fields before the truncated one to be kept: %+v", s)
+	}
+	if path, offset := p.Progress(); path != "B" || offset != 3 {
+		t.Errorf("Incorrect progress: %v %v", path, offset)
+	}
+}
+
+func TestFixedRunPlan(t *testing.T) {
+	plan := planFor(reflect.TypeOf(fixedRunStruct{}))
+	runs := []int{5, 4, 3, 2, 1, 0, 2, 1}
+	for i, f := range plan.fields {
+		if f.run != runs[i] {
+			t.Errorf("Incorrect run for %v: %v", f.Name, f.run)
+		}
+	}
+	if plan.fields[0].runSize != 12 {
+		t.Errorf("Incorrect run size: %v", plan.fields[0].runSize)
+	}
+}
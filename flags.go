@@ -0,0 +1,51 @@
+package bingo
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Reads a single integer of the type named by the `flags` tag and expands it
+// into the bool fields of a struct. Each bool field selects its bit with a
+// `bit` tag, bit 0 being the least significant one:
+//
+//	type Permissions struct {
+//		Read  bool `bit:"2"`
+//		Write bool `bit:"1"`
+//		Exec  bool `bit:"0"`
+//	}
+//
+//	Mode Permissions `flags:"uint16"`
+func (p *Parser) readFlags(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	raw, err := p.readStorage("flags", getTag(fieldtyp, "flags"), fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+
+	var word uint64
+	if raw.CanInt() {
+		word = uint64(raw.Int())
+	} else {
+		word = raw.Uint()
+	}
+	nbits := raw.Type().Bits()
+
+	typ := fieldval.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		flagtyp := typ.Field(i)
+		if len(flagtyp.PkgPath) > 0 {
+			continue
+		}
+		if flagtyp.Type.Kind() != reflect.Bool {
+			return p.errorf(ErrUnsupportedType, nil, "Error reading flags into '%v %v'. Field '%v %v' is not a bool.", fieldtyp.Name, fieldtyp.Type, flagtyp.Name, flagtyp.Type)
+		}
+
+		bitstr := getTag(flagtyp, "bit")
+		bit, err := strconv.ParseUint(bitstr, 0, 8)
+		if err != nil || int(bit) >= nbits {
+			return p.errorf(ErrInvalidTag, nil, "Invalid value for `bit` tag on '%v %v': %v. Expected an integer between 0 and %v.", flagtyp.Name, flagtyp.Type, bitstr, nbits-1)
+		}
+		fieldval.Field(i).SetBool(word&(1<<bit) != 0)
+	}
+	return nil
+}
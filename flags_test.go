@@ -0,0 +1,63 @@
+package bingo
+
+import (
+	"testing"
+)
+
+type Permissions struct {
+	Read   bool `bit:"2"`
+	Write  bool `bit:"1"`
+	Exec   bool `bit:"0"`
+	Sticky bool `bit:"9"`
+}
+
+func TestFlagsField(t *testing.T) {
+	data := []byte{0x05, 0x02}
+	s := struct {
+		Mode Permissions `flags:"uint16"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Mode.Read && !s.Mode.Write && s.Mode.Exec && s.Mode.Sticky) {
+		t.Error("Error expanding flags:", s.Mode)
+	}
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestFlagsBitOutOfRange(t *testing.T) {
+	s := struct {
+		Mode Permissions `flags:"uint8"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid value for `bit` tag on 'Sticky bool': 9. Expected an integer between 0 and 7." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestFlagsNonBool(t *testing.T) {
+	s := struct {
+		Mode struct {
+			Read uint8 `bit:"0"`
+		} `flags:"uint8"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading flags into 'Mode struct { Read uint8 \"bit:\\\"0\\\"\" }'. Field 'Read uint8' is not a bool." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
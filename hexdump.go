@@ -0,0 +1,77 @@
+package bingo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const hexdumpRow = 16
+
+// SetErrorContext makes errors carry the n bytes of input on either side of
+// the offset where they occurred, shown as a hex dump after the message. The
+// bytes are read back from the parser's reader, which must implement
+// io.ReaderAt, like *os.File and *bytes.Reader do; other readers get no
+// context. Offsets are taken as positions in the reader. Pass 0 to turn the
+// context off again.
+func (p *Parser) SetErrorContext(n uint) {
+	p.errorContext = uint64(n)
+}
+
+// Reads the bytes around the parser's offset into the error's Context.
+func (p *Parser) attachContext(perr *ParseError) {
+	ra, ok := unbuffered(p.src).(io.ReaderAt)
+	if !ok || p.errorContext == 0 {
+		return
+	}
+	start := uint64(0)
+	if perr.Offset > p.errorContext {
+		start = perr.Offset - p.errorContext
+	}
+	buf := make([]byte, perr.Offset+p.errorContext-start)
+	n, _ := ra.ReadAt(buf, int64(start))
+	perr.Context, perr.ContextOffset = buf[:n], start
+}
+
+// Renders the context as rows of 16 bytes, marking the row that holds the
+// error's offset with '>'.
+func (err *ParseError) hexdump() string {
+	var b strings.Builder
+	first := err.ContextOffset - err.ContextOffset%hexdumpRow
+	last := err.ContextOffset + uint64(len(err.Context))
+	for row := first; row < last; row += hexdumpRow {
+		mark := byte(' ')
+		if err.Offset >= row && err.Offset < row+hexdumpRow {
+			mark = '>'
+		}
+		fmt.Fprintf(&b, "\n%c ", mark)
+		writeHexRow(&b, row, err.Context, err.ContextOffset)
+	}
+	return b.String()
+}
+
+// Writes the row of bytes starting at offset row, for data that starts at
+// offset start. Positions outside of data are left blank.
+func writeHexRow(b *strings.Builder, row uint64, data []byte, start uint64) {
+	fmt.Fprintf(b, "%08x ", row)
+	last := start + uint64(len(data))
+	var ascii [hexdumpRow]byte
+	for i := uint64(0); i < hexdumpRow; i++ {
+		ascii[i] = ' '
+		if i == hexdumpRow/2 {
+			b.WriteByte(' ')
+		}
+		pos := row + i
+		if pos < start || pos >= last {
+			b.WriteString("   ")
+			continue
+		}
+		c := data[pos-start]
+		fmt.Fprintf(b, " %02x", c)
+		ascii[i] = '.'
+		if c >= 0x20 && c < 0x7f {
+			ascii[i] = c
+		}
+	}
+	fmt.Fprintf(b, "  |%s|", strings.TrimRight(string(ascii[:]), " "))
+}
@@ -0,0 +1,43 @@
+package bingo
+
+import (
+	"testing"
+)
+
+func TestErrorContext(t *testing.T) {
+	s := struct {
+		Name  [4]byte
+		Magic uint16 `magic:"0x4B4F"`
+	}{}
+	p := newParserData([]byte{'b', 'i', 'n', 'g', 'X', 'X', 0, 1})
+	p.SetErrorContext(4)
+
+	err := p.EmitReadStruct(&s)
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatal("Expected a ParseError:", err)
+	}
+	if perr.ContextOffset != 2 || string(perr.Context) != "ngXX\x00\x01" {
+		t.Errorf("Invalid context: %v %q", perr.ContextOffset, perr.Context)
+	}
+	expected := "Magic mismatch in 'Magic uint16' at offset 4: expected 0x4B4F, got 0x5858.\n" +
+		"> 00000000        6e 67 58 58 00 01                           |  ngXX..|"
+	if perr.Error() != expected {
+		t.Errorf("Invalid message:\n%v", perr.Error())
+	}
+}
+
+func TestErrorContextDisabled(t *testing.T) {
+	s := struct {
+		Magic uint16 `magic:"0x4B4F"`
+	}{}
+	p := newParserData([]byte{'X', 'X'})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Context != nil || perr.Error() != "Magic mismatch in 'Magic uint16' at offset 0: expected 0x4B4F, got 0x5858." {
+			t.Error(err)
+		}
+	} else {
+		t.Error("Expected an error")
+	}
+}
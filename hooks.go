@@ -0,0 +1,131 @@
+package bingo
+
+import (
+	"reflect"
+	"strings"
+)
+
+var (
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	parserType = reflect.TypeOf((*Parser)(nil))
+)
+
+// Calls the method named by a hook tag on the struct ptrval points to, passing
+// the *Parser followed by args. If the method's last result is an error and
+// it isn't nil, parsing is aborted. The remaining results are returned.
+func (p *Parser) callHook(tag, methodname string, ptrval reflect.Value, args ...reflect.Value) ([]reflect.Value, error) {
+	methodname = strings.TrimSuffix(methodname, "()")
+	meth, ok := methodByName(ptrval.Type(), methodname)
+	if !ok {
+		return nil, p.errorf(ErrTagReference, nil, "Method '%v()' for '%v' not found. Referenced from a `%v` tag.", methodname, ptrval.Type(), tag)
+	}
+	p.logHook(methodname, ptrval.Type())
+
+	in := append([]reflect.Value{ptrval, reflect.ValueOf(p)}, args...)
+	results, err := p.callMethod(meth, in)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(results); n > 0 && results[n-1].Type() == errorType {
+		if err := results[n-1]; !err.IsNil() {
+			if ferr := p.fail(reflect.Value{}, ErrVerifyFailed, err.Interface().(error), "Aborting: method '%v' on '%v' returned error '%v'", methodname, ptrval.Type(), err.Interface()); ferr != nil {
+				return nil, ferr
+			}
+		}
+		results = results[:n-1]
+	}
+	return results, nil
+}
+
+// Calls a method defined by the user, turning a panic inside it into a
+// ParseError that names the method and the struct it was called on.
+func (p *Parser) callMethod(meth reflect.Method, args []reflect.Value) ([]reflect.Value, error) {
+	var results []reflect.Value
+	err := p.guardHook(meth.Name, args[0].Type(), func() {
+		results = meth.Func.Call(args)
+	})
+	return results, err
+}
+
+func (p *Parser) guardHook(name string, typ reflect.Type, call func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(*ParseError); ok {
+				// raised by the method with RaiseError
+				err = perr
+				return
+			}
+			cause, _ := r.(error)
+			err = p.errorf(ErrHookPanicked, cause, "Method '%v' on '%v' panicked at offset %v: %v", name, typ, p.offset, r)
+		}
+	}()
+	call()
+	return nil
+}
+
+// Fills a field tagged with `compute` with the result of the named method,
+// which is called once the preceding fields are parsed. Nothing is read from
+// the stream:
+//
+//	Packed  uint16
+//	Version uint8 `compute:"UnpackVersion"`
+//
+//	func (h *Header) UnpackVersion(p *bingo.Parser) uint8
+//
+// The method may also return an error as its second result.
+func (p *Parser) computeField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, computekey string) error {
+	results, err := p.callHook("compute", computekey, ptrval)
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || !results[0].Type().AssignableTo(fieldtyp.Type) {
+		return p.errorf(nil, nil, "Error computing field '%v %v'. Method '%v' must return a single %v.", fieldtyp.Name, fieldtyp.Type, computekey, fieldtyp.Type)
+	}
+	fieldval.Set(results[0])
+	return nil
+}
+
+// Calls the method named by a `transform` tag with a pointer to the field that
+// was just read, so the value can be normalized in place:
+//
+//	Flags uint32 `transform:"MaskFlags"`
+//
+//	func (h *Header) MaskFlags(p *bingo.Parser, flags *uint32)
+//
+// The method may return an error to abort parsing.
+func (p *Parser) transformField(fieldval reflect.Value, ptrval reflect.Value, transformkey string) error {
+	_, err := p.callHook("transform", transformkey, ptrval, fieldval.Addr())
+	return err
+}
+
+// Calls the method named by a `before` tag right before its field is read,
+// with the offset the field starts at:
+//
+//	Body Body `before:"LogBody"`
+//
+//	func (f *File) LogBody(p *bingo.Parser, offset uint64)
+//
+// The offset may also be taken as a uint, as it used to be. The fields before
+// it are already filled in. The method may return an error to abort parsing.
+func (p *Parser) callBefore(beforekey string, ptrval reflect.Value) error {
+	offset := reflect.ValueOf(p.offset)
+	if meth, ok := methodByName(ptrval.Type(), strings.TrimSuffix(beforekey, "()")); ok && meth.Type.NumIn() == 3 && offset.CanConvert(meth.Type.In(2)) {
+		offset = offset.Convert(meth.Type.In(2))
+	}
+	_, err := p.callHook("before", beforekey, ptrval, offset)
+	return err
+}
+
+// Hands the reading of a field tagged with `parser` over to the named method,
+// for fields the tags can't describe:
+//
+//	Pixels []byte `parser:"Decompress"`
+//
+//	func (img *Image) Decompress(p *bingo.Parser, pixels *[]byte) error
+//
+// The method reads from the stream through the Parser, e.g. with
+// EmitReadNBytes, so that offsets stay correct.
+func (p *Parser) readCustomField(fieldval reflect.Value, ptrval reflect.Value, parserkey string) error {
+	_, err := p.callHook("parser", parserkey, ptrval, fieldval.Addr())
+	return err
+}
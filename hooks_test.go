@@ -0,0 +1,322 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+)
+
+type computeHeader struct {
+	Packed  uint16
+	Version uint8 `compute:"UnpackVersion"`
+	Minor   uint8 `compute:"UnpackMinor()"`
+	Next    uint8
+}
+
+func (h *computeHeader) UnpackVersion(p *Parser) uint8 {
+	return uint8(h.Packed >> 8)
+}
+
+func (h *computeHeader) UnpackMinor(p *Parser) (uint8, error) {
+	return uint8(h.Packed), nil
+}
+
+func TestComputeField(t *testing.T) {
+	data := []byte{0x05, 0x02, 7}
+	s := computeHeader{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Version != 2 || s.Minor != 5 || s.Next != 7 {
+		t.Error("Error computing fields:", s)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type computeError struct {
+	Packed  uint8
+	Version uint8 `compute:"UnpackVersion"`
+}
+
+func (h *computeError) UnpackVersion(p *Parser) (uint8, error) {
+	return 0, errors.New("bad version")
+}
+
+func TestComputeFieldError(t *testing.T) {
+	s := computeError{}
+	p := newParserData([]byte{1})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Aborting: method 'UnpackVersion' on '*bingo.computeError' returned error 'bad version'" {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type transformHeader struct {
+	Flags uint16  `transform:"MaskFlags" assert:"!=0"`
+	Name  [4]byte `transform:"Reverse()"`
+}
+
+func (h *transformHeader) MaskFlags(p *Parser, flags *uint16) {
+	*flags &= 0x0F
+}
+
+func (h *transformHeader) Reverse(p *Parser, name *[4]byte) error {
+	for i, j := 0, len(name)-1; i < j; i, j = i+1, j-1 {
+		name[i], name[j] = name[j], name[i]
+	}
+	return nil
+}
+
+func TestTransformField(t *testing.T) {
+	data := []byte{0xF3, 0xF0, 'd', 'c', 'b', 'a'}
+	s := transformHeader{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Flags != 3 {
+		t.Error("Error transforming flags:", s.Flags)
+	}
+	if string(s.Name[:]) != "abcd" {
+		t.Error("Error transforming name:", s.Name)
+	}
+	if p.offset != 6 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type beforeRecord struct {
+	Kind    uint8
+	Payload uint16 `before:"MarkPayload"`
+	seen    []uint
+}
+
+func (r *beforeRecord) MarkPayload(p *Parser, offset uint) error {
+	if r.Kind == 0 {
+		return errors.New("missing kind")
+	}
+	r.seen = append(r.seen, offset)
+	return nil
+}
+
+func TestBeforeHook(t *testing.T) {
+	s := beforeRecord{}
+	p := newParserData([]byte{1, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.seen) != 1 || s.seen[0] != 1 {
+		t.Error("Error calling before hook:", s.seen)
+	}
+	if s.Payload != 2 {
+		t.Error("Error parsing field after hook:", s.Payload)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestBeforeHookError(t *testing.T) {
+	s := beforeRecord{}
+	p := newParserData([]byte{0, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Aborting: method 'MarkPayload' on '*bingo.beforeRecord' returned error 'missing kind'" {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type parseHooks struct {
+	Version uint8
+	Size    uint16
+	calls   []string
+}
+
+func (h *parseHooks) BeforeParse(p *Parser) error {
+	h.calls = append(h.calls, "before")
+	h.Size = 0xFFFF
+	return nil
+}
+
+func (h *parseHooks) AfterParse(p *Parser) error {
+	h.calls = append(h.calls, "after")
+	if h.Version > 2 {
+		return errors.New("unsupported version")
+	}
+	return nil
+}
+
+func TestParseHooks(t *testing.T) {
+	s := parseHooks{}
+	p := newParserData([]byte{1, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.calls) != 2 || s.calls[0] != "before" || s.calls[1] != "after" {
+		t.Error("Error calling hooks:", s.calls)
+	}
+	if s.Size != 2 {
+		t.Error("Error parsing field after BeforeParse:", s.Size)
+	}
+}
+
+func TestAfterParseError(t *testing.T) {
+	s := parseHooks{}
+	p := newParserData([]byte{3, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Aborting: method 'AfterParse' on '*bingo.parseHooks' returned error 'unsupported version'" {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type indexedRecord struct {
+	Value uint8 `after:"CheckValue"`
+}
+
+func (r *indexedRecord) CheckValue(p *Parser, index int) error {
+	if int(r.Value) != index {
+		return errors.New("value doesn't match index")
+	}
+	return nil
+}
+
+func TestAfterHookIndex(t *testing.T) {
+	s := struct {
+		Records []indexedRecord `len:"3"`
+		Sized   []indexedRecord `size:"2"`
+		Single  indexedRecord
+	}{}
+	p := newParserData([]byte{0, 1, 2, 0, 1, 0})
+
+	if err := p.EmitReadStruct(&s); err == nil {
+		t.Error("Expected an error for the record outside of a slice")
+	} else if perr, ok := err.(*ParseError); !ok || perr.Error() != "Aborting: method 'CheckValue' on '*bingo.indexedRecord' returned error 'value doesn't match index'" {
+		t.Error("Incorrect error:", err)
+	}
+
+	if len(s.Records) != 3 || len(s.Sized) != 2 {
+		t.Error("Error parsing records:", s.Records, s.Sized)
+	}
+	if p.offset != 6 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type customRecord struct {
+	Length uint8
+	Digits []uint8 `parser:"ReadDigits"`
+	Next   uint8
+}
+
+func (r *customRecord) ReadDigits(p *Parser, digits *[]uint8) error {
+	buf, err := p.EmitReadNBytes(int(r.Length))
+	if err != nil {
+		return err
+	}
+	for _, b := range buf {
+		*digits = append(*digits, b>>4, b&0xF)
+	}
+	return nil
+}
+
+func TestCustomFieldParser(t *testing.T) {
+	s := customRecord{}
+	p := newParserData([]byte{2, 0x12, 0x34, 9})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Digits) != 4 || s.Digits[0] != 1 || s.Digits[3] != 4 {
+		t.Error("Error parsing custom field:", s.Digits)
+	}
+	if s.Next != 9 {
+		t.Error("Error parsing field after custom field:", s.Next)
+	}
+	if p.offset != 4 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type panickingHooks struct {
+	Length uint8
+	Data   []byte `len:"DataLen()"`
+	table  []int
+}
+
+func (h *panickingHooks) DataLen(p *Parser) int {
+	return h.table[h.Length]
+}
+
+func TestHookPanic(t *testing.T) {
+	s := panickingHooks{}
+	p := newParserData([]byte{3, 0, 0, 0})
+
+	err := p.EmitReadStruct(&s)
+	if !errors.Is(err, ErrHookPanicked) {
+		t.Fatal("Expected ErrHookPanicked, got", err)
+	}
+	if err.Error() != "Method 'DataLen' on '*bingo.panickingHooks' panicked at offset 1: runtime error: index out of range [3] with length 0" {
+		t.Error("Incorrect error:", err)
+	}
+	if perr := err.(*ParseError); perr.FieldPath != "Data" {
+		t.Error("Invalid field path:", perr.FieldPath)
+	}
+}
+
+type raisingHooks struct {
+	Length uint8
+	Data   []byte `parser:"ReadData"`
+}
+
+func (h *raisingHooks) ReadData(p *Parser, data *[]byte) {
+	if h.Length > 2 {
+		p.RaiseError2("Length %v is too large", h.Length)
+	}
+}
+
+func TestHookRaiseError(t *testing.T) {
+	s := raisingHooks{}
+	p := newParserData([]byte{3})
+
+	err := p.EmitReadStruct(&s)
+	if err == nil || err.Error() != "Length 3 is too large" {
+		t.Fatal("Incorrect error:", err)
+	}
+	if perr := err.(*ParseError); perr.FieldPath != "Data" || perr.Offset != 1 {
+		t.Error("Invalid error location:", perr.FieldPath, perr.Offset)
+	}
+}
@@ -0,0 +1,114 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"math"
+)
+
+// NewParserBytes returns a parser over data held in memory. Its size being
+// known, lengths read from the data are checked against it before anything
+// is allocated for them, `offset` tags can seek anywhere in it, and Peek
+// hands out the bytes ahead without copying them.
+//
+// With the AliasInput option, the contents of []byte fields point into data
+// rather than being copied out of it, which saves copying large payloads.
+// data must then be left unchanged for as long as the parsed structs are in
+// use, and the contents of the fields must not be changed either; copy them
+// first if they are to be.
+func NewParserBytes(data []byte, byteOrder ByteOrder, options ParseOptions) *Parser {
+	p := NewParser(bytes.NewReader(data), byteOrder, options)
+	p.input, p.inputSize = data, uint64(len(data))
+	return p
+}
+
+// Returns the bytes of the input the parser may read next, up to the end of
+// the innermost size-limited region, or false if it isn't parsing from a
+// byte slice or is reading from a copy of some of it.
+func (p *Parser) unreadInput() ([]byte, bool) {
+	if p.input == nil {
+		return nil, false
+	}
+	r, limit := p.r, int64(math.MaxInt64)
+	for lr, ok := r.(*io.LimitedReader); ok; lr, ok = r.(*io.LimitedReader) {
+		r, limit = lr.R, min(limit, lr.N)
+	}
+	if r != p.src {
+		return nil, false
+	}
+	rest := p.input[len(p.input)-r.(*bytes.Reader).Len():]
+	return rest[:min(int64(len(rest)), limit)], true
+}
+
+// Consumes the next nbytes bytes of the input and returns them in place,
+// with the AliasInput option. Returns false if they can't be had that way,
+// including when the input ends before them, for the caller to read them
+// the usual way and report the error.
+func (p *Parser) aliasInput(nbytes int) ([]byte, bool) {
+	if !p.alias {
+		return nil, false
+	}
+	rest, ok := p.unreadInput()
+	if !ok || len(rest) < nbytes {
+		return nil, false
+	}
+	if err := seekReader(p.r, int64(nbytes)); err != nil {
+		return nil, false
+	}
+	p.offset += uint64(nbytes)
+	// Appending to the field mustn't overwrite the input
+	return rest[:nbytes:nbytes], true
+}
+
+// Reads the contents of a []byte field of nbytes bytes, or those of a slice
+// to be parsed from them.
+func (p *Parser) readFieldBytes(nbytes int) ([]byte, error) {
+	if buf, ok := p.aliasInput(nbytes); ok {
+		return buf, nil
+	}
+	return p.EmitReadNBytes(nbytes)
+}
+
+// Reads the rest of the stream for a slice sized with `size:"<inf>"`.
+func (p *Parser) readAllFieldBytes() ([]byte, error) {
+	if rest, ok := p.unreadInput(); ok {
+		if buf, ok := p.aliasInput(len(rest)); ok {
+			return buf, nil
+		}
+	}
+	return p.EmitReadAll()
+}
+
+// Peek returns the next n bytes of the stream without consuming them, e.g.
+// for a hook to look at a tag before deciding how to parse what follows.
+// Parsers made with NewParserBytes return them from the input without
+// copying, so they must not be modified. Otherwise the reader has to
+// implement io.Seeker, to be seeked back once they're read.
+func (p *Parser) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, p.errorf(ErrInvalidLength, nil, "Unable to peek at %v bytes at offset %v.", n, p.offset)
+	}
+	p.alignBits()
+	if rest, ok := p.unreadInput(); ok {
+		if len(rest) < n {
+			err := io.ErrUnexpectedEOF
+			if len(rest) == 0 {
+				err = io.EOF
+			}
+			return rest, p.shortReadError(err, int64(n), int64(len(rest)))
+		}
+		return rest[:n:n], nil
+	}
+	if _, ok := seekableLeft(p.r); !ok {
+		return nil, p.errorf(nil, nil, "Unable to peek at offset %v. Peeking requires a reader that implements io.Seeker.", p.offset)
+	}
+	buf := make([]byte, n)
+	got, err := io.ReadFull(p.r, buf)
+	if serr := seekReader(p.r, -int64(got)); serr != nil {
+		return nil, p.wrapError(serr)
+	}
+	if err != nil {
+		return buf[:got], p.shortReadError(err, int64(n), int64(got))
+	}
+	return buf, nil
+}
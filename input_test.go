@@ -0,0 +1,124 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type blob struct {
+	NameLen uint8
+	Name    []byte `len:"NameLen"`
+	Size    uint8
+	Data    []byte `size:"Size"`
+	Sum     uint16
+}
+
+var blobData = []byte{3, 'a', 'b', 'c', 2, 0xAA, 0xBB, 1, 2}
+
+func TestNewParserBytes(t *testing.T) {
+	data := bytes.Clone(blobData)
+	var s blob
+	if err := NewParserBytes(data, LittleEndian, Default).EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if string(s.Name) != "abc" || !bytes.Equal(s.Data, []byte{0xAA, 0xBB}) || s.Sum != 0x201 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+	data[1] = 'x'
+	if string(s.Name) != "abc" {
+		t.Error("Expected a copy of the input:", string(s.Name))
+	}
+}
+
+func TestAliasInput(t *testing.T) {
+	data := bytes.Clone(blobData)
+	var s blob
+	p := NewParserBytes(data, LittleEndian, AliasInput)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if &s.Name[0] != &data[1] || &s.Data[0] != &data[5] {
+		t.Error("Expected the fields to point into the input")
+	}
+	if s.Sum != 0x201 || p.Offset() != uint64(len(data)) {
+		t.Errorf("Incorrect struct: %+v at offset %v", s, p.Offset())
+	}
+	// Appending mustn't overwrite what follows in the input
+	s.Name = append(s.Name, 'd')
+	if data[4] != 2 {
+		t.Error("Input overwritten:", data)
+	}
+}
+
+func TestAliasInputRegion(t *testing.T) {
+	type inner struct {
+		Len  uint8
+		Name []byte `len:"Len"`
+	}
+	var s struct {
+		Size  uint8
+		Inner inner `size:"Size"`
+	}
+	data := []byte{3, 2, 'a', 'b', 9}
+	if err := NewParserBytes(data, LittleEndian, AliasInput).EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if &s.Inner.Name[0] != &data[2] {
+		t.Error("Expected the name to point into the input")
+	}
+
+	// The name runs past the end of the region, though not of the input
+	p := NewParserBytes([]byte{2, 3, 'a', 'b', 'c'}, LittleEndian, AliasInput)
+	if err := p.EmitReadStruct(&s); !errors.Is(err, ErrInvalidLength) {
+		t.Error("Expected an invalid length error. Got", err)
+	}
+}
+
+func TestNewParserBytesLength(t *testing.T) {
+	var s blob
+	p := NewParserBytes([]byte{0xFF, 'a'}, LittleEndian, AliasInput)
+	if err := p.EmitReadStruct(&s); !errors.Is(err, ErrInvalidLength) {
+		t.Error("Expected an invalid length error. Got", err)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	for _, p := range []*Parser{NewParserBytes(blobData, LittleEndian, Default), newParserData(blobData)} {
+		b, err := p.Peek(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, blobData[:4]) || p.Offset() != 0 {
+			t.Errorf("Incorrect peek: %v at offset %v", b, p.Offset())
+		}
+		var s blob
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		if string(s.Name) != "abc" {
+			t.Errorf("Incorrect struct: %+v", s)
+		}
+		if _, err := p.Peek(1); !errors.Is(err, ErrUnexpectedEOF) {
+			t.Error("Expected an unexpected EOF error at the end. Got", err)
+		}
+	}
+}
+
+func TestPeekNotSeekable(t *testing.T) {
+	p := NewParser(bytes.NewBufferString("abc"), LittleEndian, Default)
+	if _, err := p.Peek(1); err == nil {
+		t.Error("Expected an error")
+	}
+}
+
+func TestPeekNegative(t *testing.T) {
+	for _, p := range []*Parser{NewParserBytes(blobData, LittleEndian, Default), newParserData(blobData)} {
+		if _, err := p.Peek(-1); !errors.Is(err, ErrInvalidLength) {
+			t.Error("Incorrect error:", err)
+		}
+		if p.Offset() != 0 {
+			t.Error("Invalid offset:", p.Offset())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package bingo
+
+// Longest string or byte slice that is interned with the Intern option.
+// Longer values are seldom repeated, and looking them up costs more than it
+// saves.
+const maxInterned = 128
+
+// Most strings, and most byte slices, kept by a parser with the Intern
+// option. Values first seen after that are read as if the option was off, so
+// that data with few repeated values can't make the parser grow without
+// bound.
+const maxInternedValues = 4096
+
+// With the Intern option, strings and byte slices of up to maxInterned bytes
+// that hold the same bytes share their memory, for formats where the same
+// small values, such as tag or field names, come up over and over. The
+// values seen are kept for as long as the parser, or until Reset, across
+// calls to EmitReadStruct, up to maxInternedValues of each. Byte slices
+// shared this way must not be modified.
+//
+// Returns the interned string holding the bytes of buf.
+func (p *Parser) internString(buf []byte) string {
+	if !p.intern || len(buf) > maxInterned {
+		return string(buf)
+	}
+	if s, ok := p.interned[string(buf)]; ok {
+		return s
+	}
+	if p.interned == nil {
+		p.interned = make(map[string]string)
+	} else if len(p.interned) >= maxInternedValues {
+		return string(buf)
+	}
+	s := string(buf)
+	p.interned[s] = s
+	return s
+}
+
+// Returns the interned byte slice holding the bytes of buf, or buf itself
+// without the Intern option or if it's longer than maxInterned. Otherwise
+// the result never shares the memory of buf, which the caller may reuse.
+func (p *Parser) internBytes(buf []byte) []byte {
+	if !p.intern || len(buf) > maxInterned {
+		return buf
+	}
+	if b, ok := p.internedBytes[string(buf)]; ok {
+		return b
+	}
+	// No room to append to, so that appending doesn't change what's shared
+	b := make([]byte, len(buf))
+	copy(b, buf)
+	if p.internedBytes == nil {
+		p.internedBytes = make(map[string][]byte)
+	} else if len(p.internedBytes) >= maxInternedValues {
+		return b
+	}
+	p.internedBytes[string(b)] = b
+	return b
+}
@@ -0,0 +1,83 @@
+package bingo
+
+import (
+	"testing"
+	"unsafe"
+)
+
+type taggedValue struct {
+	NameLen uint8
+	Name    string `len:"NameLen"`
+	KeyLen  uint8
+	Key     []byte `len:"KeyLen"`
+	Size    uint8
+	Value   []byte `size:"Size"`
+	Unit    []byte `terminator:"0x00"`
+}
+
+var taggedValues = []byte{
+	2, 'i', 'd', 1, 'k', 1, 'v', 'm', 0,
+	2, 'i', 'd', 1, 'k', 1, 'v', 'm', 0,
+}
+
+func TestIntern(t *testing.T) {
+	for _, opts := range []ParseOptions{Default, Intern} {
+		var s struct {
+			Values []taggedValue `len:"2"`
+		}
+		if err := NewParserBytes(taggedValues, LittleEndian, opts).EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		a, b := s.Values[0], s.Values[1]
+		if a.Name != "id" || string(a.Key) != "k" || string(a.Value) != "v" || string(a.Unit) != "m" {
+			t.Fatalf("Incorrect struct: %+v", a)
+		}
+		shared := unsafe.StringData(a.Name) == unsafe.StringData(b.Name) && &a.Key[0] == &b.Key[0] && &a.Value[0] == &b.Value[0] && &a.Unit[0] == &b.Unit[0]
+		if shared != (opts == Intern) {
+			t.Errorf("Expected the values to be shared only with the Intern option. Options %v, shared %v", opts, shared)
+		}
+		if opts == Intern && cap(a.Key) != 1 {
+			t.Error("Expected no room to append to a shared slice:", cap(a.Key))
+		}
+	}
+}
+
+func TestInternAcrossStructs(t *testing.T) {
+	p := NewParserBytes(taggedValues, LittleEndian, Intern)
+	var a, b taggedValue
+	if err := p.EmitReadStruct(&a); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EmitReadStruct(&b); err != nil {
+		t.Fatal(err)
+	}
+	if unsafe.StringData(a.Name) != unsafe.StringData(b.Name) {
+		t.Error("Expected the names to be shared")
+	}
+
+	p.Reset(nil)
+	if len(p.interned) != 0 || len(p.internedBytes) != 0 {
+		t.Error("Expected Reset to drop the interned values")
+	}
+}
+
+func TestInternLimit(t *testing.T) {
+	var s struct {
+		Values []taggedValue `len:"2"`
+	}
+	p := NewParserBytes(taggedValues, LittleEndian, Intern)
+	for i := 0; i < maxInternedValues; i++ {
+		p.internString([]byte{byte(i), byte(i >> 8), 's'})
+		p.internBytes([]byte{byte(i), byte(i >> 8), 'b'})
+	}
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	a, b := s.Values[0], s.Values[1]
+	if len(p.interned) != maxInternedValues || len(p.internedBytes) != maxInternedValues {
+		t.Errorf("Expected no more values to be kept. Kept %v strings and %v byte slices", len(p.interned), len(p.internedBytes))
+	}
+	if a.Name != "id" || string(a.Key) != "k" || string(b.Key) != "k" || &a.Key[0] == &b.Key[0] {
+		t.Errorf("Expected values past the limit to be read on their own: %+v %+v", a, b)
+	}
+}
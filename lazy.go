@@ -0,0 +1,149 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// Lazy is a field type for payloads that needn't be decoded along with the
+// rest of the struct, such as the contents of the entries of a directory
+// that is scanned for one of them. While the containing struct is parsed, a
+// Lazy field only records where its bytes are and skips them, and they are
+// decoded when Value is called:
+//
+//	type Entry struct {
+//		Name     [16]byte
+//		Size     uint32
+//		Contents bingo.Lazy[[]byte] `size:"Size"`
+//	}
+//
+// T must be a struct type, or a slice of bytes or of structs as for other
+// fields with a `size` tag. The tag gives the number of bytes the field takes
+// up, and may only be left out for structs of a fixed size. The parser's
+// reader has to implement io.ReaderAt, which it keeps for Value to read from,
+// as *os.File and *bytes.Reader do.
+type Lazy[T any] struct {
+	r       io.ReaderAt
+	pos     int64 // position of the field in r
+	offset  uint64
+	size    uint64
+	order   binary.ByteOrder
+	options ParseOptions
+}
+
+// Wraps slices read by Lazy fields, which take up the whole field
+type lazySlice[T any] struct {
+	Value T `size:"<inf>"`
+}
+
+type lazyField interface {
+	Size() uint64
+	valueType() reflect.Type
+	setLazy(p *Parser, r io.ReaderAt, pos int64, offset, size uint64)
+}
+
+func (l *Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l *Lazy[T]) setLazy(p *Parser, r io.ReaderAt, pos int64, offset, size uint64) {
+	l.r, l.pos, l.offset, l.size = r, pos, offset, size
+	l.order, l.options = p.byteOrder, p.options
+}
+
+// Offset returns the stream offset of the field.
+func (l *Lazy[T]) Offset() uint64 {
+	return l.offset
+}
+
+// Size returns the size of the field in bytes.
+func (l *Lazy[T]) Size() uint64 {
+	return l.size
+}
+
+// Value decodes the field with the byte order and the options of the parser
+// that read the containing struct. Each call decodes it anew, and may be
+// made from any goroutine once the parse is over.
+func (l *Lazy[T]) Value() (T, error) {
+	var v T
+	if l.r == nil {
+		return v, errors.New("bingo: Lazy field was not set by a parser")
+	}
+	p := NewParser(io.NewSectionReader(l.r, l.pos, int64(l.size)), l.order, l.options)
+	// Offsets are those of the stream the field was read from
+	p.offset, p.inputSize = l.offset, l.offset+l.size
+
+	var err error
+	if reflect.TypeOf(v).Kind() == reflect.Struct {
+		if err = p.emitReadStruct(&v); err == nil && p.offset != p.inputSize {
+			err = p.errorf(ErrSizeMismatch, nil, "Error reading exactly %v bytes into %T at offset %v. Actual bytes read: %v", l.size, v, l.offset, p.offset-l.offset)
+		}
+	} else {
+		var s lazySlice[T]
+		err = p.emitReadStruct(&s)
+		v = s.Value
+	}
+	if err != nil && l.options&Panicky != 0 {
+		panic(err)
+	}
+	return v, err
+}
+
+// Returns the position in the parser's reader of the current offset, or
+// false if it can't be told. The reader needn't have been at its start when
+// the parse began, and the parser may be reading from a copy of a region.
+func (p *Parser) readerPos() (int64, bool) {
+	r := p.r
+	for lr, ok := r.(*io.LimitedReader); ok; lr, ok = r.(*io.LimitedReader) {
+		r = lr.R
+	}
+	if br, ok := r.(*bytes.Reader); ok && r != p.src {
+		// elements of a slice read from a buffered region
+		return p.copyPos + br.Size() - int64(br.Len()), p.copyPos >= 0
+	}
+	seeker, ok := p.src.(io.Seeker)
+	if !ok {
+		return int64(p.offset), true
+	}
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	return pos, err == nil
+}
+
+func (p *Parser) readLazy(lazy lazyField, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	typ := lazy.valueType()
+	if kind := typ.Kind(); kind != reflect.Struct && kind != reflect.Slice {
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Lazy fields must hold a struct or a slice.", fieldtyp.Name, fieldtyp.Type)
+	}
+
+	var size uint64
+	if sizekey := getTag(fieldtyp, "size"); len(sizekey) > 0 {
+		var err error
+		if size, err = p.parseLengthTag("size", sizekey, fieldtyp, ptrval, -1); err != nil {
+			return err
+		}
+	} else if n := fixedSize(typ); typ.Kind() == reflect.Struct && n >= 0 {
+		size = uint64(n)
+	} else {
+		return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Lazy fields require a `size` tag unless they hold a struct of a fixed size.", fieldtyp.Name, fieldtyp.Type)
+	}
+
+	p.alignBits()
+	r, ok := unbuffered(p.src).(io.ReaderAt)
+	if !ok {
+		return p.errorf(nil, nil, "Unable to read field '%v %v' at offset %v. Lazy fields require a reader that implements io.ReaderAt.", fieldtyp.Name, fieldtyp.Type, p.offset)
+	}
+	pos, ok := p.readerPos()
+	if !ok {
+		return p.errorf(nil, nil, "Unable to read field '%v %v' at offset %v. Its position in the reader can't be told.", fieldtyp.Name, fieldtyp.Type, p.offset)
+	}
+
+	offset := p.offset
+	if err := p.skipBytes(size); err != nil {
+		return err
+	}
+	lazy.setLazy(p, r, pos, offset, size)
+	return nil
+}
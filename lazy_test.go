@@ -0,0 +1,150 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type lazyHeader struct {
+	Kind    uint8
+	Version uint16
+}
+
+type lazyEntry struct {
+	Size     uint8
+	Contents Lazy[[]byte] `size:"Size"`
+	Header   Lazy[lazyHeader]
+}
+
+type lazyDirectory struct {
+	Count   uint8
+	Entries []lazyEntry `len:"Count"`
+	Trailer uint8
+}
+
+var lazyData = []byte{2,
+	4, 1, 0, 2, 0, 7, 1, 0,
+	2, 3, 0, 8, 2, 0,
+	0xEE}
+
+func TestLazyField(t *testing.T) {
+	var s lazyDirectory
+	p := newParserData(lazyData)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Trailer != 0xEE || p.Offset() != uint64(len(lazyData)) {
+		t.Errorf("Incorrect struct: %+v at offset %v", s, p.Offset())
+	}
+	second := s.Entries[1]
+	if second.Contents.Offset() != 10 || second.Contents.Size() != 2 || second.Header.Offset() != 12 || second.Header.Size() != 3 {
+		t.Errorf("Incorrect fields: %+v", second)
+	}
+
+	contents, err := s.Entries[0].Contents.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(contents, []byte{1, 0, 2, 0}) {
+		t.Error("Incorrect contents:", contents)
+	}
+	header, err := second.Header.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != (lazyHeader{8, 2}) {
+		t.Error("Incorrect header:", header)
+	}
+	if p.Offset() != uint64(len(lazyData)) {
+		t.Error("Decoding moved the parser:", p.Offset())
+	}
+}
+
+func TestLazyFieldFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "lazy")
+	// The directory doesn't start the file
+	if err := os.WriteFile(name, append([]byte{0xFF}, lazyData...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.Seek(1, 0)
+
+	var s lazyDirectory
+	if err := NewParser(f, BigEndian, Default).EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := s.Entries[1].Contents.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(contents, []byte{3, 0}) {
+		t.Error("Incorrect contents:", contents)
+	}
+	// in the parser's byte order
+	if header, err := s.Entries[1].Header.Value(); err != nil || header != (lazyHeader{8, 0x200}) {
+		t.Error("Incorrect header:", header, err)
+	}
+}
+
+func TestLazyFieldInCopiedRegion(t *testing.T) {
+	var s struct {
+		Count   uint8
+		Entries []lazyEntry `size:"<inf>"`
+	}
+	p := newParserData(lazyData[:len(lazyData)-1])
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	second := s.Entries[1]
+	if second.Contents.Offset() != 10 || second.Header.Offset() != 12 {
+		t.Errorf("Incorrect fields: %+v", second)
+	}
+	contents, err := second.Contents.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(contents, []byte{3, 0}) {
+		t.Error("Incorrect contents:", contents)
+	}
+	if header, err := second.Header.Value(); err != nil || header != (lazyHeader{8, 2}) {
+		t.Error("Incorrect header:", header, err)
+	}
+}
+
+func TestLazyFieldErrors(t *testing.T) {
+	var s lazyDirectory
+	p := NewParser(bytes.NewBuffer(lazyData), LittleEndian, Default)
+	if err := p.EmitReadStruct(&s); err == nil {
+		t.Error("Expected an error without io.ReaderAt")
+	}
+
+	var sized struct {
+		Size   uint8
+		Header Lazy[lazyHeader] `size:"Size"`
+	}
+	if err := newParserData([]byte{4, 1, 2, 0, 9}).EmitReadStruct(&sized); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sized.Header.Value(); !errors.Is(err, ErrSizeMismatch) {
+		t.Error("Expected a size mismatch error. Got", err)
+	}
+
+	var unset Lazy[lazyHeader]
+	if _, err := unset.Value(); err == nil {
+		t.Error("Expected an error for a Lazy field that wasn't parsed")
+	}
+
+	var bad struct {
+		Value Lazy[uint32] `size:"4"`
+	}
+	if err := newParserData(lazyData).EmitReadStruct(&bad); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an unsupported type error. Got", err)
+	}
+}
@@ -0,0 +1,105 @@
+package bingo
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Reads the length that immediately precedes the data of a field tagged with
+// `lenprefix`. The tag names the encoding of the length: a fixed-size integer
+// type such as uint16, varint for an unsigned LEB128 value, gitofs for the
+// offset encoding used by git packfiles, or ber for ASN.1 length octets.
+func (p *Parser) readLengthPrefix(prefixkey string, fieldtyp reflect.StructField, ptrval reflect.Value) (uint64, error) {
+	var (
+		value uint64
+		err   error
+	)
+	switch prefixkey {
+	case "varint":
+		value, err = p.readUvarint(fieldtyp)
+	case "gitofs":
+		value, err = p.readGitOffset(fieldtyp)
+	case "ber":
+		value, err = p.readBERLength(fieldtyp)
+	default:
+		var raw reflect.Value
+		if raw, err = p.readStorage("lenprefix", prefixkey, fieldtyp, ptrval); err != nil {
+			return 0, err
+		}
+		if raw.CanInt() {
+			if err := p.checkNonNegative(raw, "lenprefix", prefixkey, fieldtyp); err != nil {
+				return 0, err
+			}
+			value = uint64(raw.Int())
+		} else {
+			value = raw.Uint()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return p.adjustLength(value, fieldtyp)
+}
+
+// Like parseRefTag, but for the `len` and `size` tags, whose value is then
+// corrected by the field's `lenadjust` tag.
+func (p *Parser) parseLengthTag(tag string, tagstr string, fieldtyp reflect.StructField, ptrval reflect.Value, index int) (uint64, error) {
+	value, err := p.parseRefTag(tag, tagstr, fieldtyp, ptrval, index)
+	if err != nil {
+		return 0, err
+	}
+	if tag == "len" || tag == "size" {
+		return p.adjustLength(value, fieldtyp)
+	}
+	return value, nil
+}
+
+// Adds the signed amount in the field's `lenadjust` tag to a declared length.
+// Formats often store a length that also counts the length field itself or a
+// fixed header, as in `len:"Length" lenadjust:"-4"`.
+func (p *Parser) adjustLength(length uint64, fieldtyp reflect.StructField) (uint64, error) {
+	adjkey := getTag(fieldtyp, "lenadjust")
+	if len(adjkey) == 0 {
+		return length, nil
+	}
+	adj, err := strconv.ParseInt(adjkey, 0, 0)
+	if err != nil {
+		return 0, p.errorf(ErrInvalidTag, nil, "Invalid value for `lenadjust` tag: %v. Expected a signed integer.", adjkey)
+	}
+	adjusted := int64(length) + adj
+	if adjusted < 0 {
+		return 0, p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Length %v adjusted by %v is negative.", fieldtyp.Name, fieldtyp.Type, length, adj)
+	}
+	return uint64(adjusted), nil
+}
+
+// Reads ASN.1 BER/DER length octets. The short form is a single byte below
+// 0x80; the long form is 0x80 plus the number of big-endian length bytes that
+// follow. The indefinite form (a lone 0x80) isn't supported.
+func (p *Parser) readBERLength(fieldtyp reflect.StructField) (uint64, error) {
+	var buf [8]byte
+	if err := p.EmitReadFull(buf[:1]); err != nil {
+		return 0, err
+	}
+	first := buf[0]
+	if first < 0x80 {
+		return uint64(first), nil
+	}
+
+	n := int(first & 0x7F)
+	if n == 0 {
+		return 0, p.errorf(nil, nil, "Error reading BER length for '%v %v'. Indefinite lengths are not supported.", fieldtyp.Name, fieldtyp.Type)
+	}
+	if n > len(buf) {
+		return 0, p.errorf(nil, nil, "Error reading BER length for '%v %v'. Length of %v bytes overflows 64 bits.", fieldtyp.Name, fieldtyp.Type, n)
+	}
+
+	if err := p.EmitReadFull(buf[:n]); err != nil {
+		return 0, err
+	}
+	var value uint64
+	for _, b := range buf[:n] {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
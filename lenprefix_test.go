@@ -0,0 +1,212 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLenPrefix(t *testing.T) {
+	data := []byte{3, 'a', 'b', 'c',
+		2, 0, 1, 0, 2, 0,
+		0x82, 0x01}
+	data = append(data, make([]byte, 130)...)
+	s := struct {
+		Name   string   `lenprefix:"uint8"`
+		Values []uint16 `lenprefix:"uint16"`
+		Blob   []byte   `lenprefix:"varint"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Name != "abc" {
+		t.Error("Error parsing length-prefixed string:", s.Name)
+	}
+	if !isEqualu16(s.Values, []uint16{1, 2}) {
+		t.Error("Error parsing length-prefixed slice:", s.Values)
+	}
+	if len(s.Blob) != 130 {
+		t.Error("Error parsing varint-prefixed slice:", len(s.Blob))
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestStringLen(t *testing.T) {
+	s := struct {
+		Length uint8
+		Name   string `len:"Length"`
+	}{}
+	p := newParserData([]byte{2, 'h', 'i'})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Name != "hi" {
+		t.Error("Error parsing string with len tag:", s.Name)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLenPrefixConflict(t *testing.T) {
+	s := struct {
+		Data []byte `len:"Length" lenprefix:"uint8"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error parsing field 'Data []uint8'. Can't combine `lenprefix` with `len` or `size` tags." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestVarintOverflow(t *testing.T) {
+	data := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F}
+	s := struct {
+		Data []byte `lenprefix:"varint"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading varint for 'Data []uint8'. Value overflows 64 bits." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestLenPrefixBER(t *testing.T) {
+	data := []byte{2, 'h', 'i',
+		0x82, 0x01, 0x00}
+	data = append(data, make([]byte, 256)...)
+	s := struct {
+		Short []byte `lenprefix:"ber"`
+		Long  []byte `lenprefix:"ber"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if string(s.Short) != "hi" {
+		t.Error("Error parsing short-form BER length:", s.Short)
+	}
+	if len(s.Long) != 256 {
+		t.Error("Error parsing long-form BER length:", len(s.Long))
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLenPrefixBERIndefinite(t *testing.T) {
+	s := struct {
+		Data []byte `lenprefix:"ber"`
+	}{}
+	p := newParserData([]byte{0x80, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading BER length for 'Data []uint8'. Indefinite lengths are not supported." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLenAdjust(t *testing.T) {
+	data := []byte{6, 'a', 'b', // length counts the 4-byte header
+		5, 'c', 'd', 'e', // prefix counts itself and a 2-byte header
+		2, 'f'}
+	s := struct {
+		Length uint8
+		Name   string `len:"Length" lenadjust:"-4"`
+		Data   []byte `lenprefix:"uint8" lenadjust:"-2"`
+		Count  uint8
+		Rest   []byte `size:"Count" lenadjust:"-0x1"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Name != "ab" {
+		t.Error("Error adjusting len:", s.Name)
+	}
+	if string(s.Data) != "cde" {
+		t.Error("Error adjusting lenprefix:", s.Data)
+	}
+	if string(s.Rest) != "f" {
+		t.Error("Error adjusting size:", s.Rest)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLenAdjustNegative(t *testing.T) {
+	s := struct {
+		Length uint8
+		Data   []byte `len:"Length" lenadjust:"-4"`
+	}{}
+	p := newParserData([]byte{2, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Data []uint8'. Length 2 adjusted by -4 is negative." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestNegativeLength(t *testing.T) {
+	s := struct {
+		Count int16
+		Data  []uint32 `len:"Count"`
+	}{}
+	p := newParserData([]byte{0xFE, 0xFF, 1, 0, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrInvalidLength) || perr.Error() != "Error reading field 'Data []uint32'. Negative value -2 for `len` tag from 'Count'." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestNegativeLenPrefix(t *testing.T) {
+	s := struct {
+		Data []byte `lenprefix:"int8"`
+	}{}
+	p := newParserData([]byte{0xFF, 'a'})
+
+	if err := p.EmitReadStruct(&s); !errors.Is(err, ErrInvalidLength) {
+		t.Error("Incorrect error:", err)
+	}
+}
@@ -0,0 +1,83 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// SetInputSize tells the parser how many bytes the input holds, so that
+// lengths read from the data can be checked against it before anything is
+// allocated for them. Inside size-limited regions they are checked against
+// the size of the region as well.
+func (p *Parser) SetInputSize(size uint64) {
+	p.inputSize = size
+}
+
+// Returns how many bytes are left to read, or false if that isn't known.
+func (p *Parser) bytesLeft() (uint64, bool) {
+	left, known := uint64(0), false
+	if p.inputSize > 0 {
+		if p.offset < p.inputSize {
+			left = p.inputSize - p.offset
+		}
+		known = true
+	}
+
+	var n uint64
+	switch r := p.r.(type) {
+	case *io.LimitedReader:
+		n = uint64(r.N)
+	case *bytes.Reader:
+		if r == p.src {
+			return left, known
+		}
+		// elements of a slice read from a buffered region
+		n = uint64(r.Len())
+	default:
+		return left, known
+	}
+	if !known || n < left {
+		left = n
+	}
+	return left, true
+}
+
+// Reports whether the input is known to hold n more bytes, from its size or
+// by seeking to its end.
+func (p *Parser) inputHolds(n uint64) bool {
+	if left, ok := p.bytesLeft(); ok {
+		return left >= n
+	}
+	left, ok := seekableLeft(p.r)
+	return ok && uint64(left) >= n
+}
+
+// Fails before a slice or buffer is made for a declared length that the data
+// left can't hold, as happens when a corrupt count reads as 0xFFFFFFFF.
+func (p *Parser) checkLength(length uint64, elemsize int, fieldtyp reflect.StructField) error {
+	left, ok := p.bytesLeft()
+	if !ok {
+		return nil
+	}
+	hi, need := bits.Mul64(length, uint64(elemsize))
+	if hi != 0 || need > left {
+		if hi != 0 {
+			need = math.MaxUint64
+		}
+		return p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Length %v needs %v bytes, but only %v are left.", fieldtyp.Name, fieldtyp.Type, length, need, left)
+	}
+	return nil
+}
+
+// Returns a length read from the data as an int, for a slice or a buffer
+// that is to hold that many elements, failing if it doesn't fit, as happens
+// for lengths of 2GB and more on 32-bit platforms.
+func (p *Parser) intLength(length uint64, fieldtyp reflect.StructField) (int, error) {
+	if length > math.MaxInt {
+		return 0, p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Length %v is too large to be held in memory.", fieldtyp.Name, fieldtyp.Type, length)
+	}
+	return int(length), nil
+}
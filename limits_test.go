@@ -0,0 +1,231 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestLengthExceedsRegion(t *testing.T) {
+	s := struct {
+		Size  uint8
+		Block struct {
+			Count uint32
+			Items []uint16 `len:"Count"`
+		} `size:"Size"`
+	}{}
+	p := newParserData([]byte{8, 0xFF, 0xFF, 0xFF, 0xFF, 1, 0, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrInvalidLength) || perr.Error() != "Error reading field 'Items []uint16'. Length 4294967295 needs 8589934590 bytes, but only 4 are left." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 5 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLengthExceedsInput(t *testing.T) {
+	s := struct {
+		Length uint32
+		Name   string `len:"Length"`
+	}{}
+	p := newParserData([]byte{0, 0, 0, 0x80, 'a', 'b'})
+	p.SetInputSize(6)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Name string'. Length 2147483648 needs 2147483648 bytes, but only 2 are left." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestLengthWithinInput(t *testing.T) {
+	s := struct {
+		Length uint8
+		Name   string `len:"Length"`
+	}{}
+	p := newParserData([]byte{2, 'a', 'b'})
+	p.SetInputSize(3)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+	if s.Name != "ab" {
+		t.Error("Error parsing field:", s.Name)
+	}
+}
+
+func TestBogusSizeUnknownInput(t *testing.T) {
+	if strconv.IntSize == 32 {
+		t.Skip("2 GiB is too large to be held in memory here")
+	}
+	s := struct {
+		Size uint32
+		Data []byte `size:"Size"`
+	}{}
+	// The size of the input isn't known, so the length can't be checked
+	// up front
+	data := append([]byte{0, 0, 0, 0x80}, make([]byte, 3*readChunk)...)
+	p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := p.EmitReadStruct(&s)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrUnexpectedEOF) || err.Error() != "Unexpected end of data while reading 'Data []uint8' of struct { Size uint32; Data []uint8 \"size:\\\"Size\\\"\" } at offset 4: expected 2147483648 bytes, got 3145728." {
+		t.Error("Incorrect error:", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16*readChunk {
+		t.Errorf("Allocated %v bytes", allocated)
+	}
+}
+
+func TestLengthTooLarge(t *testing.T) {
+	s := struct {
+		Size uint64
+		Data []byte `size:"Size"`
+	}{}
+	data := []byte{0, 0, 0, 0, 0, 0, 0, 0x80, 1, 2, 3}
+	p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+	if err := p.EmitReadStruct(&s); !errors.Is(err, ErrInvalidLength) || err.Error() != "Error reading field 'Data []uint8'. Length 9223372036854775808 is too large to be held in memory." {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+// A reader of size zero bytes, but for those in marks
+type sparseReader struct {
+	size, pos int64
+	marks     map[int64]byte
+}
+
+func (r *sparseReader) Read(buf []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(buf)), r.size-r.pos))
+	for i := range buf[:n] {
+		buf[i] = r.marks[r.pos+int64(i)]
+	}
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *sparseReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+func TestOffsetsPastFourGiB(t *testing.T) {
+	const far = 5 << 30
+	s := struct {
+		Where uint64
+		Value uint16 `offset:"Where"`
+		Gap   Skip   `skip:"Where"`
+		Last  uint8
+	}{}
+	r := &sparseReader{size: far + 16, marks: map[int64]byte{
+		3: far >> 24 & 0xFF, 4: far >> 32, far: 1, far + 1: 2, far + 8: 7,
+	}}
+	p := NewParser(r, LittleEndian, Default)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Where != far || s.Value != 0x201 || s.Last != 7 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+	if p.Offset() != far+9 {
+		t.Error("Incorrect offset:", p.Offset())
+	}
+}
+
+func TestLargeSize(t *testing.T) {
+	s := struct {
+		Size uint32
+		Data []byte `size:"Size"`
+	}{}
+	data := append([]byte{0, 0, 0x50, 0}, make([]byte, 0x500000)...)
+	data[len(data)-1] = 0xAB
+	p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Data) != 0x500000 || s.Data[len(s.Data)-1] != 0xAB || p.Offset() != uint64(len(data)) {
+		t.Errorf("Incorrect data: %v bytes, offset %v", len(s.Data), p.Offset())
+	}
+}
+
+func TestBogusMapLength(t *testing.T) {
+	s := struct {
+		Count   uint32
+		Entries map[uint32]uint32 `len:"Count"`
+	}{}
+	data := []byte{0, 0, 0, 0x10, 1, 0, 0, 0, 2, 0, 0, 0}
+	p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := p.EmitReadStruct(&s)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Incorrect error:", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Allocated %v bytes", allocated)
+	}
+}
+
+func TestBogusLengthUnknownInput(t *testing.T) {
+	s := struct {
+		Count   uint32
+		Samples []uint32 `len:"Count"`
+	}{}
+	// 1<<30 samples declared, one and a half there
+	data := []byte{0, 0, 0, 0x40, 1, 0, 0, 0, 2, 0}
+	p := NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := p.EmitReadStruct(&s)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrUnexpectedEOF) || err.Error() != "Unexpected end of data while reading 'Samples []uint32' of struct { Count uint32; Samples []uint32 \"len:\\\"Count\\\"\" } at offset 4: expected 4294967296 bytes, got 6." {
+		t.Error("Incorrect error:", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Allocated %v bytes", allocated)
+	}
+	if len(s.Samples) != 1 || s.Samples[0] != 1 {
+		t.Error("Expected the complete elements to be kept:", s.Samples)
+	}
+
+	b := struct {
+		Count uint32
+		Data  []byte `len:"Count"`
+	}{}
+	p = NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+	runtime.ReadMemStats(&before)
+	err = p.EmitReadStruct(&b)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Incorrect error:", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Allocated %v bytes", allocated)
+	}
+}
@@ -0,0 +1,75 @@
+package bingo
+
+import "encoding/binary"
+
+// Mark is a position of a parser to go back to with ResetToMark.
+type Mark struct {
+	offset    uint64
+	pos       int64 // position of the reader, or -1 if it can't seek
+	byteOrder binary.ByteOrder
+	bits      byte
+	nbits     uint
+
+	// what the parser had recorded by then
+	nerrors, nwarnings, nspans int
+}
+
+// Offset returns the offset the mark was taken at.
+func (m Mark) Offset() uint64 {
+	return m.offset
+}
+
+// Mark remembers the current position of the parser, for ResetToMark to go
+// back to if what comes next doesn't parse. This makes it possible to try one
+// layout after another where the data doesn't tell which one it holds:
+//
+//	m := p.Mark()
+//	if err := p.EmitReadStruct(&v2); err != nil {
+//		if err := p.ResetToMark(m); err != nil {
+//			return err
+//		}
+//		err = p.EmitReadStruct(&v1)
+//	}
+func (p *Parser) Mark() Mark {
+	pos, ok := readerPosition(p.r)
+	if !ok {
+		pos = -1
+	}
+	return Mark{
+		offset:    p.offset,
+		pos:       pos,
+		byteOrder: p.byteOrder,
+		bits:      p.bits.cur,
+		nbits:     p.bits.nbits,
+		nerrors:   len(p.errors),
+		nwarnings: len(p.warnings),
+		nspans:    len(p.fieldMap),
+	}
+}
+
+// ResetToMark moves the parser back to where m was taken, by seeking its
+// reader, and restores the byte order and the unread bits of a bit field it
+// had then. Errors collected with the CollectErrors option, warnings and the
+// field map entries recorded since are dropped. The reader has to implement
+// io.Seeker, and inside a size-limited region the mark has to lie within the
+// region.
+func (p *Parser) ResetToMark(m Mark) error {
+	// The reader is seeked by how far it has moved since the mark rather than
+	// by how far the offset has, which a failed read may not have advanced
+	cur, ok := readerPosition(p.r)
+	if !ok || m.pos < 0 {
+		return p.errorf(nil, nil, "Unable to go back to offset %v. Resetting to a mark requires a reader that implements io.Seeker.", m.offset)
+	}
+	if m.offset < p.regionStart {
+		return p.errorf(nil, nil, "Unable to go back to offset %v. It's outside of the enclosing size-limited region.", m.offset)
+	}
+	if err := p.seekBy(m.pos-cur, int64(m.offset)); err != nil {
+		return err
+	}
+	p.byteOrder = m.byteOrder
+	p.bits.cur, p.bits.nbits = m.bits, m.nbits
+	p.errors = p.errors[:min(m.nerrors, len(p.errors))]
+	p.warnings = p.warnings[:min(m.nwarnings, len(p.warnings))]
+	p.fieldMap = p.fieldMap[:min(m.nspans, len(p.fieldMap))]
+	return nil
+}
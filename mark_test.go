@@ -0,0 +1,93 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestMark(t *testing.T) {
+	// A blob whose name claims more bytes than there are, which is then
+	// read as a plain header instead
+	data := []byte{9, 'a', 'b', 0x12, 0x34}
+	// Read directly and through the parser's buffer
+	for _, r := range []io.Reader{bytes.NewReader(data), &readCounter{ReadSeeker: bytes.NewReader(data)}} {
+		p := NewParser(r, LittleEndian, Default)
+		m := p.Mark()
+
+		var b blob
+		if err := p.EmitReadStruct(&b); !errors.Is(err, ErrUnexpectedEOF) {
+			t.Fatal("Expected an unexpected EOF error. Got", err)
+		}
+		if err := p.ResetToMark(m); err != nil {
+			t.Fatal(err)
+		}
+		if p.Offset() != m.Offset() {
+			t.Error("Incorrect offset after resetting:", p.Offset())
+		}
+		var header struct {
+			Len  uint8
+			Name [2]byte
+			ID   uint16
+		}
+		if err := p.EmitReadStruct(&header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Len != 9 || string(header.Name[:]) != "ab" || header.ID != 0x3412 {
+			t.Errorf("Incorrect header: %+v", header)
+		}
+	}
+}
+
+func TestMarkAfterSizedField(t *testing.T) {
+	p := newParserData([]byte{1, 9, 3})
+	m := p.Mark()
+	var sized struct {
+		N  uint8
+		In struct {
+			A uint8 `assert:"==7"`
+		} `size:"N"`
+	}
+	if err := p.EmitReadStruct(&sized); !errors.Is(err, ErrAssertionFailed) {
+		t.Fatal("Expected an assertion error. Got", err)
+	}
+	if err := p.ResetToMark(m); err != nil {
+		t.Fatal(err)
+	}
+	var plain struct{ A, B, C uint8 }
+	if err := p.EmitReadStruct(&plain); err != nil {
+		t.Fatal(err)
+	}
+	if plain.A != 1 || plain.B != 9 || plain.C != 3 {
+		t.Errorf("Incorrect struct: %+v", plain)
+	}
+}
+
+func TestMarkRestoresState(t *testing.T) {
+	p := newParserData([]byte{0xA5, 1, 2, 3})
+	if _, err := p.BitReader().ReadBits(4); err != nil {
+		t.Fatal(err)
+	}
+	m := p.Mark()
+	p.SetByteOrder(BigEndian)
+	if _, err := p.ReadUint16(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ResetToMark(m); err != nil {
+		t.Fatal(err)
+	}
+	if bits, err := p.BitReader().ReadBits(4); err != nil || bits != 5 {
+		t.Error("Incorrect bits after resetting:", bits, err)
+	}
+	if n, err := p.ReadUint16(); err != nil || n != 0x0201 {
+		t.Error("Incorrect little-endian value after resetting:", n, err)
+	}
+
+	p = NewParser(bytes.NewBuffer([]byte{1, 2}), LittleEndian, Default)
+	m = p.Mark()
+	p.ReadUint8()
+	if err := p.ResetToMark(m); err == nil {
+		t.Error("Expected an error resetting a reader that can't seek")
+	}
+}
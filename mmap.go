@@ -0,0 +1,67 @@
+package bingo
+
+import (
+	"fmt"
+	"os"
+)
+
+// MappedFile is a file mapped into memory read-only, for parsing large files
+// in place: with a parser from NewParser, which passes the AliasInput option
+// along, the contents of []byte fields point into the mapping instead of
+// being copied, and only the pages that are looked at get read from disk.
+//
+// The mapping belongs to the MappedFile. Fields pointing into it are valid
+// until Close is called, after which using them crashes the program, and
+// they must not be written to at any time; copy whatever is to outlive the
+// MappedFile or be modified. On systems without mmap the file is read into
+// memory instead, with the same rules.
+type MappedFile struct {
+	data   []byte
+	mapped bool
+}
+
+// MapFile maps the file with the given name into memory.
+func MapFile(name string) (*MappedFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int(info.Size())
+	if int64(size) != info.Size() {
+		return nil, fmt.Errorf("bingo: %v is too large to be mapped: %v bytes", name, info.Size())
+	}
+	if size == 0 {
+		return &MappedFile{data: []byte{}}, nil
+	}
+	data, err := mapFile(f, size)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedFile{data: data, mapped: true}, nil
+}
+
+// Bytes returns the contents of the file, which must not be modified.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// NewParser returns a parser over the contents of the file, as
+// NewParserBytes does, with the AliasInput option added to options.
+func (m *MappedFile) NewParser(byteOrder ByteOrder, options ParseOptions) *Parser {
+	return NewParserBytes(m.data, byteOrder, options|AliasInput)
+}
+
+// Close unmaps the file. Nothing read from it may be used afterwards.
+func (m *MappedFile) Close() error {
+	data, mapped := m.data, m.mapped
+	m.data, m.mapped = nil, false
+	if !mapped {
+		return nil
+	}
+	return unmapFile(data)
+}
@@ -0,0 +1,57 @@
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "blob")
+	if err := os.WriteFile(name, append(blobData, "tail"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := MapFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	var s struct {
+		Blob blob
+		Rest []byte `size:"<inf>"`
+	}
+	if err := m.NewParser(LittleEndian, Default).EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	data := m.Bytes()
+	if string(s.Blob.Name) != "abc" || &s.Blob.Name[0] != &data[1] {
+		t.Errorf("Expected the name to point into the file: %q", s.Blob.Name)
+	}
+	if string(s.Rest) != "tail" || &s.Rest[0] != &data[len(blobData)] {
+		t.Errorf("Expected the rest to point into the file: %q", s.Rest)
+	}
+	if err := m.Close(); err != nil {
+		t.Error(err)
+	}
+	if m.Bytes() != nil {
+		t.Error("Expected no contents after Close")
+	}
+}
+
+func TestMapEmptyFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(name, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := MapFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Bytes()) != 0 {
+		t.Error("Expected no contents:", m.Bytes())
+	}
+	if err := m.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,54 @@
+package bingo
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// With the Unsafe option, copies buf, which holds a value in the parser's
+// byte order, straight into the memory of val when that order is the
+// machine's and val is laid out in memory just as in buf: a number, an array
+// of numbers or a slice of either. Reports whether it did; the bytes are left
+// to be decoded with encoding/binary otherwise.
+func (p *Parser) copyNative(val reflect.Value, buf []byte) bool {
+	var mem unsafe.Pointer
+	switch {
+	case val.Kind() == reflect.Slice:
+		if !isPlainFixedType(val.Type().Elem()) || val.Len()*int(val.Type().Elem().Size()) != len(buf) {
+			return false
+		}
+		mem = val.UnsafePointer()
+	case val.CanAddr():
+		if !isPlainFixedType(val.Type()) || int(val.Type().Size()) != len(buf) {
+			return false
+		}
+		mem = val.Addr().UnsafePointer()
+	default:
+		return false
+	}
+	if len(buf) == 0 || !p.unsafe || !p.nativeOrder() {
+		return len(buf) == 0
+	}
+	copy(unsafe.Slice((*byte)(mem), len(buf)), buf)
+	return true
+}
+
+// With the Unsafe option, copies buf, holding a run of plain fixed-size
+// fields that starts with field first of the struct val, into the memory of
+// the fields when the parser's byte order is the machine's, a piece at a time
+// for the fields that lie in memory as in the stream, with no gaps between
+// them. Reports whether it did.
+func (p *Parser) copyNativeRun(val reflect.Value, plan *structPlan, first int, buf []byte) bool {
+	if !p.unsafe || !p.nativeOrder() {
+		return false
+	}
+	base := val.Addr().UnsafePointer()
+	for i := first; i < first+plan.fields[first].run; {
+		f := &plan.fields[i]
+		last := &plan.fields[i+f.packed-1]
+		n := int(last.Offset-f.Offset) + last.size
+		copy(unsafe.Slice((*byte)(unsafe.Add(base, f.Offset)), n), buf)
+		buf, i = buf[n:], i+f.packed
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type nativeStruct struct {
+	A uint16
+	B uint16
+	C [2]int32
+	D uint16
+	E uint8
+	F uint32 // aligned in memory, after a gap
+	N uint8
+	G [2]uint16 `if:"N == 2"`
+	H []uint32  `len:"N"`
+}
+
+func TestNativeCopy(t *testing.T) {
+	want := nativeStruct{1, 2, [2]int32{-3, 4}, 0xABCD, 5, 6, 2, [2]uint16{7, 8}, []uint32{9, 10}}
+	for _, opts := range []ParseOptions{Default, Unsafe} {
+		for _, order := range []ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			var buf bytes.Buffer
+			for _, v := range []interface{}{want.A, want.B, want.C, want.D, want.E, want.F, want.N, want.G, want.H} {
+				binary.Write(&buf, order, v)
+			}
+			var s nativeStruct
+			p := NewParser(&buf, order, opts)
+			if err := p.EmitReadStruct(&s); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, want) {
+				t.Errorf("Incorrect struct in %v with options %v: %+v", order, opts, s)
+			}
+		}
+	}
+}
+
+func TestPackedRuns(t *testing.T) {
+	plan := planFor(reflect.TypeOf(nativeStruct{}))
+	// The run from A is copied in two pieces
+	for i, packed := range []int{5, 4, 3, 2, 1, 2, 1} {
+		if plan.fields[i].packed != packed {
+			t.Errorf("Expected packed %v for field %v", packed, plan.fields[i].Name)
+		}
+	}
+}
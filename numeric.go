@@ -0,0 +1,180 @@
+package bingo
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Bytes of a numeric slice decoded at a time
+const numericChunk = 32 << 10
+
+// Reports whether slices of elements of type typ are read by
+// readNumericSlice.
+func isNumericElem(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Reads a slice of fixed-size numbers a chunk at a time, decoding each chunk
+// with binary.Decode. With the Unsafe option, in the standard byte orders the
+// bytes are read straight into the slice and swapped in place when they
+// don't match the machine's; otherwise they're converted by hand. Elements
+// decoded before a failure are kept, though the offset stays at the start of
+// the slice as with EmitReadFixed.
+func (p *Parser) readNumericSlice(slice reflect.Value) error {
+	p.alignBits()
+	elemsize := int(slice.Type().Elem().Size())
+	length := slice.Len()
+	if !p.unsafe {
+		return p.decodeNumericSlice(slice, length, elemsize)
+	}
+	base := slice.UnsafePointer()
+	if swap, ok := p.swapNeeded(); ok {
+		return p.readNumericSliceInPlace(base, length, elemsize, swap)
+	}
+	for done := 0; done < length; {
+		n := min(length-done, numericChunk/elemsize)
+		buf := p.scratchBuf(n * elemsize)
+		got, err := io.ReadFull(p.r, buf)
+		n = got / elemsize
+		elems := unsafe.Add(base, done*elemsize)
+		switch elemsize {
+		case 2:
+			dst := unsafe.Slice((*uint16)(elems), n)
+			for i := range dst {
+				dst[i] = p.byteOrder.Uint16(buf[2*i:])
+			}
+		case 4:
+			dst := unsafe.Slice((*uint32)(elems), n)
+			for i := range dst {
+				dst[i] = p.byteOrder.Uint32(buf[4*i:])
+			}
+		case 8:
+			dst := unsafe.Slice((*uint64)(elems), n)
+			for i := range dst {
+				dst[i] = p.byteOrder.Uint64(buf[8*i:])
+			}
+		}
+		if err != nil {
+			if err == io.EOF && done > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return p.shortReadError(err, int64(length*elemsize), int64(done*elemsize+got))
+		}
+		done += n
+	}
+	p.offset += uint64(length * elemsize)
+	return nil
+}
+
+// Reads a slice of numbers a chunk at a time, decoding the elements of each
+// with binary.Decode.
+func (p *Parser) decodeNumericSlice(slice reflect.Value, length, elemsize int) error {
+	for done := 0; done < length; {
+		n := min(length-done, numericChunk/elemsize)
+		buf := p.scratchBuf(n * elemsize)
+		got, err := io.ReadFull(p.r, buf)
+		n = got / elemsize
+		if n > 0 {
+			if _, derr := binary.Decode(buf[:n*elemsize], p.byteOrder, slice.Slice(done, done+n).Interface()); derr != nil {
+				return p.wrapError(derr)
+			}
+		}
+		if err != nil {
+			if err == io.EOF && done > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return p.shortReadError(err, int64(length*elemsize), int64(done*elemsize+got))
+		}
+		done += n
+	}
+	p.offset += uint64(length * elemsize)
+	return nil
+}
+
+// Reports whether numbers in the parser's byte order need their bytes
+// reversed to be used on this machine. Returns false as its second result if
+// the byte order is neither of the standard ones.
+func (p *Parser) swapNeeded() (swap bool, ok bool) {
+	switch p.byteOrder {
+	case binary.LittleEndian, binary.BigEndian, binary.NativeEndian:
+		return !p.nativeOrder(), true
+	}
+	return false, false
+}
+
+// Reads a slice of numbers straight into its memory, then reverses the
+// bytes of each element in place if swap is set.
+func (p *Parser) readNumericSliceInPlace(base unsafe.Pointer, length, elemsize int, swap bool) error {
+	buf := unsafe.Slice((*byte)(base), length*elemsize)
+	got, err := io.ReadFull(p.r, buf)
+	if swap {
+		p.swapBytes(base, got/elemsize, elemsize)
+	}
+	if err != nil {
+		return p.shortReadError(err, int64(len(buf)), int64(got))
+	}
+	p.offset += uint64(len(buf))
+	return nil
+}
+
+// SetParallelThreshold makes the parser split the byte swapping of numeric
+// slices of at least size bytes among as many goroutines as GOMAXPROCS, for
+// large sample or index arrays whose byte order isn't the machine's, when
+// they're read in place with the Unsafe option. Pass 0, the default, to
+// always swap on the calling goroutine.
+func (p *Parser) SetParallelThreshold(size int) {
+	p.parallelThreshold = size
+}
+
+// Bytes swapped by each goroutine at the least
+const minParallelChunk = 64 << 10
+
+// Reverses the bytes of each of the n elements of elemsize bytes at base,
+// spreading the work over several goroutines for large slices.
+func (p *Parser) swapBytes(base unsafe.Pointer, n, elemsize int) {
+	workers := runtime.GOMAXPROCS(0)
+	if p.parallelThreshold <= 0 || n*elemsize < p.parallelThreshold || workers < 2 {
+		swapBytes(base, n, elemsize)
+		return
+	}
+	chunk := max((n+workers-1)/workers, minParallelChunk/elemsize)
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			swapBytes(unsafe.Add(base, start*elemsize), min(chunk, n-start), elemsize)
+		}(start)
+	}
+	wg.Wait()
+}
+
+// Reverses the bytes of each of the n elements of elemsize bytes at base.
+func swapBytes(base unsafe.Pointer, n, elemsize int) {
+	switch elemsize {
+	case 2:
+		elems := unsafe.Slice((*uint16)(base), n)
+		for i := range elems {
+			elems[i] = bits.ReverseBytes16(elems[i])
+		}
+	case 4:
+		elems := unsafe.Slice((*uint32)(base), n)
+		for i := range elems {
+			elems[i] = bits.ReverseBytes32(elems[i])
+		}
+	case 8:
+		elems := unsafe.Slice((*uint64)(base), n)
+		for i := range elems {
+			elems[i] = bits.ReverseBytes64(elems[i])
+		}
+	}
+}
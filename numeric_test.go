@@ -0,0 +1,122 @@
+package bingo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+type sample int16
+
+func TestNumericSlices(t *testing.T) {
+	s := struct {
+		Samples []sample  `len:"2"`
+		Index   []uint32  `len:"1"`
+		Floats  []float32 `len:"1"`
+		Wide    []int64   `len:"1"`
+	}{}
+	data := []byte{0xFF, 0xFF, 2, 0, 1, 2, 3, 4, 0, 0, 0x80, 0x3F, 0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Samples) != 2 || s.Samples[0] != -1 || s.Samples[1] != 2 || s.Index[0] != 0x04030201 || s.Floats[0] != 1 || s.Wide[0] != -2 {
+		t.Errorf("Incorrect slices: %+v", s)
+	}
+	if p.Offset() != uint64(len(data)) {
+		t.Errorf("Invalid offset: %v", p.Offset())
+	}
+}
+
+func TestNumericSliceChunks(t *testing.T) {
+	const n = numericChunk // spans several chunks
+	data := make([]byte, 4+8*n)
+	binary.BigEndian.PutUint32(data, n)
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint64(data[4+8*i:], math.Float64bits(float64(i)/2))
+	}
+	s := struct {
+		Count  uint32
+		Values []float64 `len:"Count"`
+	}{}
+	p := NewParser(bytes.NewReader(data), BigEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range s.Values {
+		if v != float64(i)/2 {
+			t.Fatalf("Incorrect value %v: %v", i, v)
+		}
+	}
+}
+
+func TestNumericSliceTruncated(t *testing.T) {
+	s := struct {
+		Values []uint16 `len:"3"`
+	}{}
+	p := newParserData([]byte{1, 0, 2, 0, 3})
+
+	err := p.EmitReadStruct(&s)
+	if !errors.Is(err, ErrUnexpectedEOF) || err.Error() != "Unexpected end of data while reading 'Values []uint16' of struct { Values []uint16 \"len:\\\"3\\\"\" } at offset 0: expected 6 bytes, got 5." {
+		t.Fatal("Incorrect error:", err)
+	}
+	if s.Values[0] != 1 || s.Values[1] != 2 {
+		t.Errorf("Expected the complete elements to be kept: %v", s.Values)
+	}
+}
+
+// A byte order the parser doesn't recognize as a standard one
+type wrappedOrder struct {
+	binary.ByteOrder
+}
+
+func TestNumericSliceOrders(t *testing.T) {
+	data := []byte{0, 1, 0, 2, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 4}
+	for _, opts := range []ParseOptions{Default, Unsafe} {
+		for _, order := range []ByteOrder{BigEndian, LittleEndian, wrappedOrder{BigEndian}} {
+			s := struct {
+				A []uint16 `len:"2"`
+				B []int32  `len:"1"`
+				C []uint64 `len:"1"`
+			}{}
+			p := NewParser(bytes.NewReader(data), order, opts)
+
+			if err := p.EmitReadStruct(&s); err != nil {
+				t.Fatal(err)
+			}
+			if order == LittleEndian {
+				if s.A[0] != 0x100 || s.A[1] != 0x200 || s.B[0] != 0x3000000 || s.C[0] != 0x400000000000000 {
+					t.Errorf("Incorrect slices with %T and options %v: %+v", order, opts, s)
+				}
+			} else if s.A[0] != 1 || s.A[1] != 2 || s.B[0] != 3 || s.C[0] != 4 {
+				t.Errorf("Incorrect slices with %T and options %v: %+v", order, opts, s)
+			}
+		}
+	}
+}
+
+func TestNumericSliceParallel(t *testing.T) {
+	const n = 1 << 18
+	data := make([]byte, 4*n)
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint32(data[4*i:], uint32(i))
+	}
+	s := struct {
+		Values []uint32 `len:"262144"`
+	}{}
+	p := NewParser(bytes.NewReader(data), BigEndian, Unsafe)
+	p.SetParallelThreshold(1 << 16)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range s.Values {
+		if v != uint32(i) {
+			t.Fatalf("Incorrect value %v: %v", i, v)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package bingo
+
+import (
+	"reflect"
+)
+
+// Action tells the parser what to do about an error passed to OnError.
+type Action int
+
+const (
+	// Stop parsing and return the error
+	Abort Action = iota
+	// Reset the field to its zero value and go on
+	SkipField
+	// Keep the value the handler stored in the field and go on
+	Substitute
+)
+
+// FieldInfo describes the field an error passed to OnError occurred in.
+type FieldInfo struct {
+	Name string
+	Type reflect.Type
+	// The field itself, settable. It's invalid for errors that concern the
+	// struct as a whole, such as one returned by AfterParse.
+	Value reflect.Value
+}
+
+// Passes an error to the parser's OnError handler, if there is one, and
+// reports whether the handler chose to go on.
+func (p *Parser) handleError(perr *ParseError, fieldval reflect.Value) bool {
+	if p.OnError == nil {
+		return false
+	}
+	field := FieldInfo{Value: fieldval}
+	if n := len(p.stack); n > 0 {
+		field.Name, field.Type = p.stack[n-1].field, p.stack[n-1].fieldType
+	}
+
+	switch p.OnError(perr, field) {
+	case SkipField:
+		if fieldval.IsValid() && fieldval.CanSet() {
+			fieldval.Set(reflect.Zero(fieldval.Type()))
+		}
+		return true
+	case Substitute:
+		return true
+	}
+	return false
+}
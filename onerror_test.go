@@ -0,0 +1,63 @@
+package bingo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type onErrorRecord struct {
+	Kind    uint8 `valid:"1..4"`
+	Version uint8 `assert:"==2"`
+	Count   uint8 `max:"10"`
+	End     uint8
+}
+
+func TestOnError(t *testing.T) {
+	s := onErrorRecord{}
+	p := newParserData([]byte{9, 3, 20, 0xEE})
+	var names []string
+	p.OnError = func(err *ParseError, field FieldInfo) Action {
+		names = append(names, field.Name)
+		switch field.Name {
+		case "Kind":
+			return SkipField
+		case "Version":
+			field.Value.SetUint(2)
+			return Substitute
+		}
+		return Abort
+	}
+
+	err := p.EmitReadStruct(&s)
+	if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrAssertionFailed) || perr.FieldPath != "Count" {
+		t.Error("Expected the parse to stop at Count:", err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"Kind", "Version", "Count"}) {
+		t.Error("Invalid fields passed to the handler:", names)
+	}
+	if s.Kind != 0 || s.Version != 2 || s.Count != 20 {
+		t.Error("Error handling fields:", s)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOnErrorFatal(t *testing.T) {
+	s := onErrorRecord{}
+	p := newParserData([]byte{1, 2})
+	called := false
+	p.OnError = func(err *ParseError, field FieldInfo) Action {
+		called = true
+		return SkipField
+	}
+
+	if err := p.EmitReadStruct(&s); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Expected an unexpected EOF error:", err)
+	}
+	if called {
+		t.Error("Expected the handler not to be called for read errors")
+	}
+}
@@ -7,24 +7,14 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"log/slog"
+	"math"
 	"reflect"
-	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 )
 
-type ParseError struct {
-	text string
-}
-
-func parseError(msg string) *ParseError {
-	return &ParseError{msg}
-}
-
-func (err *ParseError) Error() string {
-	return err.text
-}
-
 type ByteOrder binary.ByteOrder
 
 var BigEndian = binary.BigEndian
@@ -36,28 +26,141 @@ const (
 	Default ParseOptions = 1 << iota
 	Strict
 	Panicky
+	LSBFirst
+	PadCheck
+	CollectErrors
+	Unsafe
+	ReuseSlices
+	AliasInput
+	Intern
 )
 
+// A struct being parsed and the offset at which it started
+type frame struct {
+	ptrval reflect.Value
+	start  uint64
+	index  int // index of the struct in a slice, or -1
+
+	// field being read
+	field     string
+	fieldType reflect.Type
+}
+
 type Parser struct {
 	r         io.Reader
+	src       io.Reader // the reader the parser was created with
 	byteOrder binary.ByteOrder
-	offset    uint
-	context   interface{}
-	depth     int
-	l         *log.Logger
 
+	// byte order the parser was created with, which Reset goes back to
+	initialOrder binary.ByteOrder
+	offset       uint64
+	context      interface{}
+	depth        int
+	stack        []frame // structs being parsed, innermost last
+	l            *log.Logger
+
+	// offset at which the innermost size-limited region started
+	regionStart uint64
+	// position in the parser's reader of the region being parsed from a copy
+	// of its bytes, or -1 if it isn't known
+	copyPos int64
+
+	// readers limiting size-limited regions, reused from one region to the
+	// next, and how many of them are in use
+	limiters []*io.LimitedReader
+	nlimits  int
+
+	// index of the slice element about to be read, or -1
+	elemIndex int
+
+	// Tags is free for hooks to keep whatever they like in. It lasts until
+	// the parser is reset.
+	//
+	// Deprecated: Use WithValue and Value, which are scoped to a parse.
 	Tags map[string]interface{}
 
-	strict  bool
-	panicky bool
+	// parameters set with WithValue for the parse to come
+	values map[interface{}]interface{}
+
+	// OnError, if set, is called with errors that don't keep the parse from
+	// going on, such as failed assertions or errors returned by hooks, and
+	// decides whether to stop. Errors in reading the data itself, like
+	// running out of it, always stop the parse.
+	OnError func(err *ParseError, field FieldInfo) Action
+
+	// options the parser was created with
+	options ParseOptions
+
+	strict   bool
+	panicky  bool
+	padcheck bool
+	collect  bool
+	unsafe   bool
+
+	// whether slices are read into the capacity they already have
+	reuseSlices bool
+
+	// input given to NewParserBytes, and whether []byte fields point into it
+	input []byte
+	alias bool
+
+	// strings and byte slices seen, with the Intern option
+	intern        bool
+	interned      map[string]string
+	internedBytes map[string][]byte
+
+	// errors recorded with the CollectErrors option
+	errors ParseErrors
+
+	// oddities that didn't make the parse fail
+	warnings []*ParseError
+
+	// whether the struct being parsed is one of a stream of records, as read
+	// by Next, which leaves the rest of the stream to the records after it
+	records bool
+
+	// stack as of the last field that was read completely, and the offset
+	// after it
+	lastStack  []frame
+	lastOffset uint64
+
+	// bytes of input to attach to errors on either side of their offset
+	errorContext uint64
+
+	// total size of the input, if known
+	inputSize uint64
+
+	// where to write a line for each field read, if anywhere
+	trace io.Writer
+
+	// structured logger, if any
+	slog *slog.Logger
+
+	// fields read by the last parse, if asked to record them
+	recordFields bool
+	fieldMap     []FieldSpan
+
+	// buffer the reader is read through, if it needs one, and its size
+	buffer     bufferedSeeker
+	bufferSize int
+
+	// reused for reads that are decoded right away
+	scratch []byte
+
+	// size in bytes from which numeric slices are swapped in parallel, or 0
+	parallelThreshold int
+
+	bits BitReader
 }
 
 func NewParser(r io.Reader, byteOrder ByteOrder, options ParseOptions) *Parser {
 	p := Parser{
-	r: r,
 	Tags: make(map[string]interface{}),
 	byteOrder: byteOrder,
-	l: log.New(os.Stderr, "[bingo]: ", 0),
+	initialOrder: byteOrder,
+	options: options,
+	l: discardLogger,
+	elemIndex: -1,
 	}
 	if options&Strict != 0 {
 		p.strict = true
@@ -65,409 +168,1655 @@ func NewParser(r io.Reader, byteOrder ByteOrder, options ParseOptions) *Parser {
 	if options&Panicky != 0 {
 		p.panicky = true
 	}
+	if options&PadCheck != 0 {
+		p.padcheck = true
+	}
+	if options&CollectErrors != 0 {
+		p.collect = true
+	}
+	if options&Unsafe != 0 {
+		p.unsafe = true
+	}
+	if options&ReuseSlices != 0 {
+		p.reuseSlices = true
+	}
+	if options&AliasInput != 0 {
+		p.alias = true
+	}
+	if options&Intern != 0 {
+		p.intern = true
+	}
+	p.bits = BitReader{p: &p, lsbFirst: options&LSBFirst != 0}
+	p.setReader(r, defaultBufferSize)
 	return &p
 }
 
-func (p *Parser) Offset() uint {
+// SetLogger makes the parser log the hooks it calls and the warnings it
+// records to l. Nothing is logged by default; to see it all on stderr, pass
+//
+//	log.New(os.Stderr, "[bingo]: ", 0)
+//
+// Passing nil turns logging off again.
+func (p *Parser) SetLogger(l *log.Logger) {
+	if l == nil {
+		l = discardLogger
+	}
+	p.l = l
+}
+
+func (p *Parser) Offset() uint64 {
 	return p.offset
 }
 
+// Progress returns the path of the last field that was read completely, as in
+// "Header.Entries[1].Name", and the offset right after it. After a failed
+// parse, this is where the data can be salvaged up to.
+func (p *Parser) Progress() (fieldPath string, offset uint64) {
+	return formatFieldPath(p.lastStack), p.lastOffset
+}
+
+// Finish checks that the input was read to the end, for structs that are
+// meant to describe a whole file or frame. It returns a ParseError matching
+// ErrSizeMismatch with the number of bytes left over otherwise. The leftover
+// bytes are consumed in the process, by seeking past them if the reader
+// implements io.Seeker or by reading them otherwise. A reader that doesn't
+// end, such as a network connection that stays open, is read from until it
+// does: to check a frame of such a stream, parse it from an io.LimitedReader
+// or within a `size` tag.
+func (p *Parser) Finish() error {
+	start := p.offset
+	var n int64
+	var err error
+	if left, ok := seekableLeft(p.r); ok {
+		if err = seekReader(p.r, left); err == nil {
+			n = left
+		}
+	} else {
+		n, err = io.Copy(io.Discard, p.r)
+	}
+	p.offset += uint64(n)
+	if err != nil {
+		return p.wrapError(err)
+	}
+	if n > 0 {
+		perr := p.parseError(ErrSizeMismatch, nil, fmt.Sprintf("%v bytes left unread at offset %v.", n, start))
+		perr.Offset = start
+		return perr
+	}
+	return nil
+}
+
+// Context returns what was passed to EmitReadStruct, the pointer to the
+// top-level struct being parsed, for hooks of nested structs to reach the
+// fields around them. ContextAs does the type assertion.
 func (p *Parser) Context() interface{} {
 	return p.context
 }
 
+// ContextAs returns the parser's context as a T, the type of the pointer
+// passed to EmitReadStruct:
+//
+//	func (e *Entry) Verify(p *bingo.Parser) error {
+//		file, err := bingo.ContextAs[*File](p)
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// It returns an error if nothing is being parsed or the top-level struct is
+// of another type.
+func ContextAs[T any](p *Parser) (T, error) {
+	ctx, ok := p.context.(T)
+	if !ok {
+		typ := reflect.TypeOf((*T)(nil)).Elem()
+		if p.context == nil {
+			return ctx, p.errorf(ErrUnsupportedType, nil, "Unable to get the context as %v. No struct is being parsed.", typ)
+		}
+		return ctx, p.errorf(ErrUnsupportedType, nil, "Unable to get the context as %v. The top-level struct is %T.", typ, p.context)
+	}
+	return ctx, nil
+}
+
+// Parent returns a pointer to the struct enclosing the one being parsed, for
+// hooks of an inner struct to consult the header of the outer one, or nil
+// while parsing the top-level struct. Elements of slices and arrays of
+// structs have the struct holding the slice as their parent.
+func (p *Parser) Parent() interface{} {
+	if n := len(p.stack); n >= 2 {
+		return p.stack[n-2].ptrval.Interface()
+	}
+	return nil
+}
+
+// Ancestors returns pointers to all the structs enclosing the one being
+// parsed, the nearest first. The last one is the top-level struct, as
+// returned by Context.
+func (p *Parser) Ancestors() []interface{} {
+	if len(p.stack) < 2 {
+		return nil
+	}
+	ancestors := make([]interface{}, 0, len(p.stack)-1)
+	for i := len(p.stack) - 2; i >= 0; i-- {
+		ancestors = append(ancestors, p.stack[i].ptrval.Interface())
+	}
+	return ancestors
+}
+
+// Skip is a zero-size marker type for reserved or unknown regions. Combined
+// with a `skip` tag it consumes bytes without storing them anywhere:
+//
+//	_ bingo.Skip `skip:"12"`
+//
+// The `skip` tag may be put on a field of any type (the field itself is then
+// left untouched), and like `len` it also accepts a field or a method.
+type Skip struct{}
+
 type Verifier interface {
 	Verify(*Parser) error
 }
 
-func (p *Parser) callVerify(methodName string, data interface{}) {
+// BeforeParser is implemented by structs that need to set up before their
+// fields are read, e.g. to seed defaults.
+type BeforeParser interface {
+	BeforeParse(*Parser) error
+}
+
+// AfterParser is implemented by structs that need to validate or finish up
+// once all of their fields are read.
+type AfterParser interface {
+	AfterParse(*Parser) error
+}
+
+// ByteOrderer is implemented by structs whose fields, including those of
+// nested structs, use a byte order other than the Parser's, as with embedded
+// sub-formats.
+type ByteOrderer interface {
+	ByteOrder() ByteOrder
+}
+
+func (p *Parser) callVerify(methodName string, data interface{}) error {
 	typ := reflect.TypeOf(data)
-	if meth, ok := typ.MethodByName(methodName); ok {
-		p.l.Printf(">>Calling %v on %v\n", methodName, typ)
-		ctxval := reflect.ValueOf(p)
-		dataval := reflect.ValueOf(data)
-		// TODO: check signature
-		retval := meth.Func.Call([]reflect.Value{dataval, ctxval})[0]
-		if !retval.IsNil() {
-			p.RaiseError2("Aborting: method '%v' on '%v' returned error '%v'", methodName, typ, retval.Interface())
-		}
-	} else {
-		p.RaiseError2("Proper '%v' method not found on the type %v.", methodName, typ)
+	meth, ok := methodByName(typ, methodName)
+	if !ok || !isVerifySignature(meth.Type) {
+		return p.errorf(ErrTagReference, nil, "Proper '%v' method not found on the type %v.", methodName, typ)
+	}
+
+	p.logHook(methodName, typ)
+	ctxval := reflect.ValueOf(p)
+	dataval := reflect.ValueOf(data)
+	args := []reflect.Value{dataval, ctxval}
+	if meth.Type.NumIn() == 3 {
+		// the method also takes the index of the struct in a slice
+		args = append(args, reflect.ValueOf(p.stack[len(p.stack)-1].index))
 	}
+	results, err := p.callMethod(meth, args)
+	if err != nil {
+		return err
+	}
+	if retval := results[0]; !retval.IsNil() {
+		return p.fail(reflect.Value{}, ErrVerifyFailed, retval.Interface().(error), "Aborting: method '%v' on '%v' returned error '%v'", methodName, typ, retval.Interface())
+	}
+	return nil
 }
 
-func (p *Parser) EmitReadStruct(data interface{}) (err error) {
-	if !p.panicky {
-		defer func() {
-			if r := recover(); r != nil {
-				if _, ok := r.(runtime.Error); ok {
-					panic(r)
-				}
+// Reports whether a method, given with its receiver, takes a *Parser and
+// optionally an int index, and returns an error.
+func isVerifySignature(meth reflect.Type) bool {
+	if meth.NumIn() < 2 || meth.NumIn() > 3 || meth.In(1) != parserType {
+		return false
+	}
+	if meth.NumIn() == 3 && meth.In(2).Kind() != reflect.Int {
+		return false
+	}
+	return meth.NumOut() == 1 && meth.Out(0) == errorType
+}
 
-				if _, ok := r.(reflect.ValueError); ok {
-					panic(r)
-				}
+// EmitReadStruct parses the stream into the struct data points to. If parsing
+// fails, the fields read before the failure keep their values; Progress tells
+// how far the parse got.
+//
+// With the Panicky option, an error is raised as a panic instead of being
+// returned.
+//
+// Structs made only of fixed-size fields, including nested structs and
+// arrays of them, are parsed without allocating memory on the heap, so that
+// a stream of small records can be decoded with a single Parser and struct
+// reused for each one. This holds for the byte array and integer checks of
+// `magic`, `assert` and friends, and for `pad`, but not while tracing,
+// logging fields or recording a field map, nor when a field fails to parse.
+func (p *Parser) EmitReadStruct(data interface{}) error {
+	p.errors, p.warnings, p.fieldMap = nil, nil, nil
+	p.lastStack, p.lastOffset = p.lastStack[:0], p.offset
+	p.context = data
+	p.depth, p.stack = 0, p.stack[:0]
+	p.elemIndex = -1
 
-				switch x := r.(type) {
-				case error:
-					err = x
-				case string:
-					err = errors.New(x)
-				default:
-					// This should not be reachable unless there's a bug in the package
-					panic(r)
-				}
-			}
-		}()
+	r, start, nlimits, copyPos := p.r, p.regionStart, p.nlimits, p.copyPos
+	err := p.emitReadStruct(data)
+	if err != nil {
+		// Leave the regions the failure happened in, so that the parser can
+		// be reset to a mark taken before
+		p.r, p.regionStart, p.copyPos = r, start, copyPos
+		p.popLimits(nlimits)
+	}
+	if err == nil && !p.records {
+		p.checkTrailingBytes()
+	}
+	if !p.records {
+		p.syncReader()
+	}
+	if p.collect {
+		err = p.collectedErrors(err)
+	}
+	if !p.records {
+		clear(p.values)
+	}
+	if err != nil && p.panicky {
+		panic(err)
 	}
+	return err
+}
 
-	p.context = data
-	p.emitReadStruct(data)
-	return
+// ReadValue is EmitReadStruct for a struct held in a reflect.Value, for code
+// that builds the values to parse into at run time. v must be a non-nil
+// pointer to a struct or an addressable struct, such as an element of a
+// slice or a field of a struct reached through a pointer.
+func (p *Parser) ReadValue(v reflect.Value) error {
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		v = v.Addr()
+	}
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct || !v.CanInterface() {
+		err := p.errorf(ErrUnsupportedType, nil, "Invalid argument %v. Expected a pointer to a struct or an addressable struct.", describeValue(v))
+		if p.panicky {
+			panic(err)
+		}
+		return err
+	}
+	return p.EmitReadStruct(v.Interface())
+}
+
+// Describes a value passed to ReadValue for an error message.
+func describeValue(v reflect.Value) string {
+	switch {
+	case !v.IsValid():
+		return "<invalid reflect.Value>"
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		return fmt.Sprintf("nil %v", v.Type())
+	case v.Kind() == reflect.Struct:
+		return fmt.Sprintf("of type %v, not addressable", v.Type())
+	case !v.CanInterface():
+		return fmt.Sprintf("of type %v, obtained through an unexported field", v.Type())
+	}
+	return fmt.Sprintf("of type %v", v.Type())
 }
 
-func (p *Parser) emitReadStruct(data interface{}) {
+func (p *Parser) emitReadStruct(data interface{}) error {
 	p.depth++
 
 	// Initial sanity checks
 	ptrtyp := reflect.TypeOf(data)
 	if ptrtyp.Kind() != reflect.Ptr {
-		p.RaiseError2("Invalid argument type %v. Expected pointer to a struct.", ptrtyp)
+		return p.errorf(ErrUnsupportedType, nil, "Invalid argument type %v. Expected pointer to a struct.", ptrtyp)
 	}
 	typ := ptrtyp.Elem()
 	if typ.Kind() != reflect.Struct {
-		p.RaiseError2("Invalid argument type %v. Expected pointer to a struct.", ptrtyp)
+		return p.errorf(ErrUnsupportedType, nil, "Invalid argument type %v. Expected pointer to a struct.", ptrtyp)
 	}
 
 	ptrval := reflect.ValueOf(data)
 	val := ptrval.Elem()
+	p.stack = append(p.stack, frame{ptrval: ptrval, start: p.offset, index: p.elemIndex})
+	top := len(p.stack) - 1
+	p.elemIndex = -1
+
+	plan := planFor(typ)
+	if p.canReadRaw(plan) {
+		if err := p.readRawStruct(ptrval, plan); err != nil {
+			return err
+		}
+		p.stack = p.stack[:top]
+		p.depth--
+		return nil
+	}
+
+	if bo, ok := data.(ByteOrderer); ok {
+		defer func(saved binary.ByteOrder) { p.byteOrder = saved }(p.byteOrder)
+		if err := p.guardHook("ByteOrder", ptrtyp, func() { p.byteOrder = bo.ByteOrder() }); err != nil {
+			return err
+		}
+	}
+
+	if bp, ok := data.(BeforeParser); ok {
+		var hookErr error
+		if err := p.guardHook("BeforeParse", ptrtyp, func() { hookErr = bp.BeforeParse(p) }); err != nil {
+			return err
+		}
+		if hookErr != nil {
+			if err := p.fail(reflect.Value{}, ErrVerifyFailed, hookErr, "Aborting: method 'BeforeParse' on '%v' returned error '%v'", ptrtyp, hookErr); err != nil {
+				return err
+			}
+		}
+	}
 
 	// Iterate over each field checking its tags and choosing the best way to
 	// read into it
-	nfields := typ.NumField()
-	for fieldIdx := 0; fieldIdx < nfields; fieldIdx++ {
-		fieldtyp := typ.Field(fieldIdx)
+	var sw *switchState
+fields:
+	for fieldIdx := 0; fieldIdx < len(plan.fields); fieldIdx++ {
+		f := &plan.fields[fieldIdx]
+		fieldtyp := f.StructField
 		fieldval := val.Field(fieldIdx)
-		indent := make([]byte, (p.depth-1)*2)
-		for indent_idx := 0; indent_idx < len(indent); indent_idx++ {
-			indent[indent_idx] = ' '
+		p.stack[top].field, p.stack[top].fieldType = fieldtyp.Name, fieldtyp.Type
+		if p.strict && len(f.tagProblem) > 0 {
+			return p.parseError(ErrInvalidTag, nil, f.tagProblem)
+		}
+		if f.run > 0 {
+			// Plain data, read along with the plain fields that follow
+			if err := p.readFixedRun(val, plan, fieldIdx); err != nil {
+				return err
+			}
+			fieldIdx += f.run - 1
+			sw = nil
+			continue
+		}
+		if f.skip {
+			// Field computed or filled in by the caller, or filled in once
+			// the field it refers to is read
+			continue
+		}
+
+		if switchkey := f.tags["switch"]; len(switchkey) > 0 {
+			// Selects which of the `case` fields that follow are read
+			var err error
+			if sw, err = p.readSwitch(switchkey, fieldtyp, ptrval); err != nil {
+				return err
+			}
+			continue
+		}
+		if casekey := f.tags["case"]; len(casekey) > 0 {
+			selected, err := p.caseSelected(sw, casekey, fieldtyp)
+			if err != nil {
+				return err
+			}
+			if !selected {
+				if defkey := f.tags["default"]; len(defkey) > 0 && fieldval.CanSet() {
+					if err := p.setDefault(fieldval, fieldtyp, defkey); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		} else {
+			sw = nil
+		}
+
+		satisfied, err := p.ifTagSatisfied(fieldtyp, ptrtyp, ptrval)
+		if err != nil {
+			return err
+		}
+		if !satisfied {
+			if defkey := f.tags["default"]; len(defkey) > 0 && fieldval.CanSet() {
+				if err := p.setDefault(fieldval, fieldtyp, defkey); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if skipkey := f.tags["skip"]; len(skipkey) > 0 {
+			// Marker for bytes that don't belong to any field
+			p.alignBits()
+			n, err := p.parseRefTag("skip", skipkey, fieldtyp, ptrval, -1)
+			if err != nil {
+				return err
+			}
+			if err := p.skipBytes(n); err != nil {
+				return err
+			}
+			continue
 		}
-		p.l.Printf("%vParsing %v %v\n", string(indent), fieldtyp.Name, fieldtyp.Type)
 
-		if !p.ifTagSatisfied(fieldtyp, ptrtyp, ptrval) {
+		if demuxkey := f.tags["demux"]; len(demuxkey) > 0 {
+			// Marker for a run of records routed into other fields. The
+			// fields records are routed to are tagged with "-" and filled
+			// from there.
+			if demuxkey != "-" {
+				p.alignBits()
+				if err := p.readDemux(fieldtyp, ptrval, demuxkey); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if computekey := f.tags["compute"]; len(computekey) > 0 {
+			// Derived from the fields before it rather than read
+			if err := p.computeField(fieldval, fieldtyp, ptrval, computekey); err != nil {
+				return err
+			}
 			continue
 		}
 
 		if len(fieldtyp.PkgPath) > 0 {
 			// unexported field. skip it
 			if p.strict {
-				p.RaiseError2("Unable to parse into '%v %v'. Unexported fields are not supported.", fieldtyp.Name, fieldtyp.Type)
+				return p.errorf(ErrUnsupportedType, nil, "Unable to parse into '%v %v'. Unexported fields are not supported.", fieldtyp.Name, fieldtyp.Type)
 			} else {
 				continue
 			}
 		}
 
+		// Fields tagged with `offset` live elsewhere in the stream. Jump
+		// there and come back once the field is read.
+		returnOffset := p.offset
+		offsetkey := f.tags["offset"]
+		if _, ok := fieldval.Addr().Interface().(refField); ok {
+			// Refs only record where their target is
+			offsetkey = ""
+		}
+		if len(offsetkey) > 0 {
+			target, err := p.parseOffsetTag(offsetkey, fieldtyp, ptrval)
+			if err != nil {
+				return err
+			}
+			if err := p.seekTo(target, fieldtyp); err != nil {
+				return err
+			}
+		}
+
+		discard, err := p.flagTag(f, "discard")
+		if err != nil {
+			return err
+		}
+		if discard {
+			p.alignBits()
+			if err := p.discardField(fieldval, fieldtyp, ptrval); err != nil {
+				return err
+			}
+			if len(offsetkey) > 0 {
+				if err := p.seekTo(returnOffset, fieldtyp); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if beforekey := f.tags["before"]; len(beforekey) > 0 {
+			if err := p.callBefore(beforekey, ptrval); err != nil {
+				return err
+			}
+		}
+
 		// Remember current offset to calculate padded bytes after reading
 		// current field
 		offset := p.offset
+		if p.trace != nil && traceBefore(fieldval) {
+			p.traceField(offset, fieldval, false)
+		}
 
-		sizekey := fieldtyp.Tag.Get("size")
-		switch fieldval.Kind() {
-		case reflect.Struct:
-			p.readFieldOfLimitedSize("size", sizekey, fieldval, fieldtyp, ptrval, -1)
-
-		case reflect.Slice:
-			// Determine the length or the size of the slice
-			lenkey := fieldtyp.Tag.Get("len")
-			if len(lenkey) > 0 && len(sizekey) > 0 {
-				p.RaiseError2("Error parsing field '%v %v'. Can't have both `len` and `size` tags on the same field.", fieldtyp.Name, fieldtyp.Type)
-			}
-
-			elemsizekey := fieldtyp.Tag.Get("elemsize")
-			if len(lenkey) > 0 {
-				// Given the length of the slice, make a new slice and parse
-				// data into it
-				length := int(p.parseRefTag("len", lenkey, fieldtyp, ptrval, -1))
-				if length > 0 {
-					p.readSliceOfLength(fieldval, length, fieldtyp, ptrval, elemsizekey)
-				}
-			} else if len(sizekey) > 0 {
-				// Given the size in bytes of the slice's contents, make a new
-				// slice and parse it by appending one element at a time
-				var buf []byte
-				if sizekey == "<inf>" {
-					// read until EOF
-					buf = p.EmitReadAll()
-				} else {
-					size := int(p.parseRefTag("size", sizekey, fieldtyp, ptrval, -1))
-					buf = p.EmitReadNBytes(size)
+		if bitskey := f.tags["bits"]; len(bitskey) > 0 {
+			if err := p.readBitField(fieldval, fieldtyp, bitskey); err != nil {
+				return err
+			}
+		} else {
+			// A run of bit fields ends on a byte boundary
+			p.alignBits()
+			optional, err := p.flagTag(f, "optional")
+			if err != nil {
+				return err
+			}
+			if optional {
+				present, err := p.readOptionalField(fieldval, fieldtyp, ptrval)
+				if err != nil {
+					return err
 				}
-				if len(buf) > 0 {
-					p.readSliceFromBytes(fieldval, fieldtyp.Type, buf)
+				if !present {
+					// The stream ended before this field; the rest of
+					// the struct is left as is
+					break fields
 				}
-			} else {
-				// Length for the slice not specified. Try parsing it as is.
-				p.EmitReadFixed(fieldval.Interface(), fieldtyp, ptrval)
+			} else if err := p.readField(fieldval, fieldtyp, ptrval); err != nil {
+				return err
 			}
+		}
 
-		case reflect.Func:
-			// Ignore functions
+		// Check constant fields before anything else gets to look at them
+		if err := p.checkAssertions(fieldval, fieldtyp, offset); err != nil {
+			return err
+		}
 
-		case reflect.Ptr:
-			p.RaiseError2("Error reading field '%v %v'. Pointer fields are not supported.", fieldtyp.Name, fieldtyp.Type)
+		if orderkey := f.tags["byteorder"]; len(orderkey) > 0 {
+			if err := p.readByteOrderMark(fieldval, fieldtyp, offset, orderkey); err != nil {
+				return err
+			}
+		}
 
-		case reflect.Bool, reflect.Chan, reflect.Map, reflect.String, reflect.UnsafePointer:
-			p.RaiseError2("Error reading field '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+		if c := plan.captures[fieldtyp.Name]; len(c) > 0 {
+			if err := p.fillCaptures(val, c, offset); err != nil {
+				return err
+			}
+		}
 
-		default:
-			// Try to read as fixed data
-			if !p.EmitReadFixed(buildPtr(fieldval), fieldtyp, ptrval) {
-				p.RaiseError(errors.New(fmt.Sprintf("Unhandled type %v", fieldval.Kind())))
+		if transformkey := f.tags["transform"]; len(transformkey) > 0 {
+			if err := p.transformField(fieldval, ptrval, transformkey); err != nil {
+				return err
 			}
 		}
+		if p.trace != nil && !traceBefore(fieldval) {
+			p.traceField(offset, fieldval, true)
+		}
+		p.slogField(offset, fieldval)
+		if p.recordFields {
+			p.recordField(offset)
+		}
 
 		// Read any remaining padding bytes before proceeding to the next field
-		padding := p.calculatePadding(fieldtyp, offset)
+		padding, err := p.calculatePadding(fieldtyp, offset)
+		if err != nil {
+			return err
+		}
 		if padding > 0 {
-			p.EmitSkipNBytes(int(padding))
+			if err := p.skipPadding(int(padding), fieldtyp); err != nil {
+				return err
+			}
+		}
+		alignment, err := p.calculateAlignment(fieldtyp)
+		if err != nil {
+			return err
+		}
+		if alignment > 0 {
+			if err := p.skipPadding(int(alignment), fieldtyp); err != nil {
+				return err
+			}
+		}
+
+		if len(offsetkey) > 0 {
+			if err := p.seekTo(returnOffset, fieldtyp); err != nil {
+				return err
+			}
 		}
 
 		// Call field's verification method if it defines one
-		if afterkey := fieldtyp.Tag.Get("after"); len(afterkey) > 0 {
-			p.callVerify(afterkey, data)
+		if afterkey := f.tags["after"]; len(afterkey) > 0 {
+			if err := p.callVerify(afterkey, data); err != nil {
+				return err
+			}
 		}
+
+		p.lastStack, p.lastOffset = append(p.lastStack[:0], p.stack...), p.offset
 	}
 
+	// Bits left over at the end of the struct are discarded as well
+	p.alignBits()
+
+	if ap, ok := data.(AfterParser); ok {
+		var hookErr error
+		if err := p.guardHook("AfterParse", ptrtyp, func() { hookErr = ap.AfterParse(p) }); err != nil {
+			return err
+		}
+		if hookErr != nil {
+			if err := p.fail(reflect.Value{}, ErrVerifyFailed, hookErr, "Aborting: method 'AfterParse' on '%v' returned error '%v'", ptrtyp, hookErr); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.stack = p.stack[:len(p.stack)-1]
 	p.depth--
+	return nil
+}
+
+// Chooses the best way to read into a single field based on its type and tags.
+func (p *Parser) readField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	if parserkey := getTag(fieldtyp, "parser"); len(parserkey) > 0 {
+		return p.readCustomField(fieldval, ptrval, parserkey)
+	}
+	if ref, ok := fieldval.Addr().Interface().(refField); ok {
+		return p.readRef(ref, fieldtyp, ptrval)
+	}
+	if lazy, ok := fieldval.Addr().Interface().(lazyField); ok {
+		return p.readLazy(lazy, fieldtyp, ptrval)
+	}
+	if varintkey := getTag(fieldtyp, "varint"); len(varintkey) > 0 {
+		return p.readVarintField(fieldval, fieldtyp, varintkey)
+	}
+
+	sizekey := getTag(fieldtyp, "size")
+	switch fieldval.Kind() {
+	case reflect.Struct:
+		if fieldval.Type() == timeType {
+			return p.readTime(fieldval, fieldtyp, ptrval)
+		} else if len(getTag(fieldtyp, "flags")) > 0 {
+			return p.readFlags(fieldval, fieldtyp, ptrval)
+		}
+		return p.readFieldOfLimitedSize("size", sizekey, fieldval, fieldtyp, ptrval, -1)
+
+	case reflect.Slice:
+		// Determine the length or the size of the slice
+		lenkey := getTag(fieldtyp, "len")
+		if len(lenkey) > 0 && len(sizekey) > 0 {
+			return p.errorf(ErrInvalidTag, nil, "Error parsing field '%v %v'. Can't have both `len` and `size` tags on the same field.", fieldtyp.Name, fieldtyp.Type)
+		}
+		prefixkey := getTag(fieldtyp, "lenprefix")
+		if len(prefixkey) > 0 && (len(lenkey) > 0 || len(sizekey) > 0) {
+			return p.errorf(ErrInvalidTag, nil, "Error parsing field '%v %v'. Can't combine `lenprefix` with `len` or `size` tags.", fieldtyp.Name, fieldtyp.Type)
+		}
+		termkey, untilkey := getTag(fieldtyp, "terminator"), getTag(fieldtyp, "until")
+		if (len(termkey) > 0 || len(untilkey) > 0) && (len(lenkey) > 0 || len(sizekey) > 0 || len(prefixkey) > 0) || len(termkey) > 0 && len(untilkey) > 0 {
+			return p.errorf(ErrInvalidTag, nil, "Error parsing field '%v %v'. Can't combine `terminator` or `until` with other length tags.", fieldtyp.Name, fieldtyp.Type)
+		}
+
+		elemsizekey := getTag(fieldtyp, "elemsize")
+		if len(termkey) > 0 {
+			return p.readTerminatedSlice(fieldval, fieldtyp, ptrval, termkey)
+		} else if len(untilkey) > 0 {
+			return p.readSliceUntil(fieldval, fieldtyp, ptrval, untilkey)
+		} else if len(lenkey) > 0 || len(prefixkey) > 0 {
+			// Given the length of the slice, make a new slice and parse
+			// data into it
+			length, _, err := p.fieldLength(fieldtyp, ptrval)
+			if err != nil {
+				return err
+			}
+			if length > 0 {
+				return p.readSliceOfLength(fieldval, length, fieldtyp, ptrval, elemsizekey)
+			}
+		} else if len(sizekey) > 0 {
+			// Given the size in bytes of the slice's contents, make a new
+			// slice and parse it by appending one element at a time
+			var buf []byte
+			var err error
+			if sizekey == "<inf>" {
+				// read until EOF
+				buf, err = p.readAllFieldBytes()
+			} else {
+				var size uint64
+				if sizekey == "<rest>" {
+					size, err = p.remainingInRegion("size", fieldtyp)
+				} else if size, err = p.parseLengthTag("size", sizekey, fieldtyp, ptrval, -1); err == nil {
+					err = p.checkLength(size, 1, fieldtyp)
+				}
+				if err != nil {
+					return err
+				}
+				if fieldval.Type().Elem().Kind() != reflect.Uint8 {
+					// The elements are parsed straight from the stream
+					// rather than from a copy of their bytes
+					return p.readSliceOfSize(fieldval, size, fieldtyp, ptrval)
+				}
+				var n int
+				if n, err = p.intLength(size, fieldtyp); err == nil {
+					buf, err = p.readFieldBytes(n)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if len(buf) > 0 {
+				err = p.readSliceFromBytes(fieldval, fieldtyp, ptrval, buf)
+			}
+			return err
+		} else {
+			// Length for the slice not specified. Try parsing it as is.
+			_, err := p.EmitReadFixed(fieldval.Interface(), fieldtyp, ptrval)
+			return err
+		}
+
+	case reflect.Map:
+		lenkey := getTag(fieldtyp, "len")
+		if len(lenkey) == 0 {
+			return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Map fields require a `len` tag.", fieldtyp.Name, fieldtyp.Type)
+		}
+		length, err := p.parseLengthTag("len", lenkey, fieldtyp, ptrval, -1)
+		if err != nil {
+			return err
+		}
+		n, err := p.intLength(length, fieldtyp)
+		if err != nil {
+			return err
+		}
+		return p.readMapOfLength(fieldval, n, fieldtyp, ptrval)
+
+	case reflect.Interface:
+		return p.readInterface(fieldval, fieldtyp, ptrval)
+
+	case reflect.Func:
+		// Ignore functions
+
+	case reflect.Ptr:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Pointer fields are not supported.", fieldtyp.Name, fieldtyp.Type)
+
+	case reflect.String:
+		return p.readString(fieldval, fieldtyp, ptrval)
+
+	case reflect.Bool, reflect.Chan, reflect.UnsafePointer:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+
+	case reflect.Array:
+		if fieldval.Type() == uuidType {
+			return p.readUUID(fieldval, fieldtyp, ptrval)
+		}
+		return p.readFixedField(fieldval, fieldtyp, ptrval)
+
+	case reflect.Int64:
+		if fieldval.Type() == durationType {
+			return p.readDuration(fieldval, fieldtyp, ptrval)
+		}
+		_, err := p.EmitReadFixed(buildPtr(fieldval), fieldtyp, ptrval)
+		return err
+
+	default:
+		// Try to read as fixed data
+		return p.readFixedField(fieldval, fieldtyp, ptrval)
+	}
+	return nil
+}
+
+// Reads a field of a type with a fixed size, such as an integer or an array of
+// integers.
+func (p *Parser) readFixedField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	ok, err := p.EmitReadFixed(buildPtr(fieldval), fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return p.errorf(ErrUnsupportedType, nil, "Unhandled type %v", fieldval.Kind())
+	}
+	return nil
 }
 
 func buildPtr(val reflect.Value) interface{} {
-	tptr := reflect.PtrTo(val.Type())
-	ptrelem := reflect.New(tptr).Elem()
-	ptrelem.Set(val.Addr())
-	return ptrelem.Interface()
+	return val.Addr().Interface()
+}
+
+var fixedSizeCache sync.Map // map[reflect.Type]int
+
+// Returns the size of a value of type typ as binary.Size does, or -1 if it
+// doesn't have a fixed size.
+func fixedSize(typ reflect.Type) int {
+	if size, ok := fixedSizeCache.Load(typ); ok {
+		return size.(int)
+	}
+	size := binary.Size(reflect.New(typ).Interface())
+	fixedSizeCache.Store(typ, size)
+	return size
 }
 
-func (p *Parser) ifTagSatisfied(fieldtyp reflect.StructField, ptrtyp reflect.Type, ptrval reflect.Value) bool {
+// Returns a buffer of n bytes for reads whose bytes are decoded right away,
+// reusing the same memory across reads.
+func (p *Parser) scratchBuf(n int) []byte {
+	if cap(p.scratch) < n {
+		p.scratch = make([]byte, n)
+	}
+	return p.scratch[:n]
+}
+
+func (p *Parser) ifTagSatisfied(fieldtyp reflect.StructField, ptrtyp reflect.Type, ptrval reflect.Value) (bool, error) {
 	// check for a condition
-	ifstr := fieldtyp.Tag.Get("if")
+	ifstr := getTag(fieldtyp, "if")
 	if len(ifstr) > 0 {
 		negate := false
-		if ifstr[0] == '!' {
+		methodname := ifstr
+		if methodname[0] == '!' {
 			negate = true
-			ifstr = ifstr[1:]
+			methodname = methodname[1:]
 		}
-		meth, ok := ptrtyp.MethodByName(ifstr)
+		meth, ok := methodByName(ptrtyp, methodname)
 		if ok {
-			// TODO: check method signature
+			if mt := meth.Type; mt.NumIn() != 2 || mt.In(1) != parserType || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+				return false, p.errorf(ErrTagReference, nil, "Invalid signature of method '%v' on '%v'. Referenced from an `if` tag in '%v %v'. Expected func(*bingo.Parser) bool.", methodname, ptrtyp, fieldtyp.Name, fieldtyp.Type)
+			}
 			ctxval := reflect.ValueOf(p)
-			result := meth.Func.Call([]reflect.Value{ptrval, ctxval})[0].Interface().(bool)
-			if negate == result {
+			results, err := p.callMethod(meth, []reflect.Value{ptrval, ctxval})
+			if err != nil {
+				return false, err
+			}
+			if negate == results[0].Bool() {
 				// Skip this field
-				return false
+				return false, nil
 			}
 		} else {
-			p.RaiseError2("Method %v on %v not found.", ifstr, ptrtyp)
+			// Not a predicate method. Evaluate it as an expression instead.
+			value, err := p.evalExpr("if", ifstr, ptrval)
+			if err != nil || value == 0 {
+				return false, err
+			}
 		}
 	}
-	return true
+	return true, nil
 }
 
-func (p *Parser) calculatePadding(fieldtyp reflect.StructField, offset uint) uint {
-	padstr := fieldtyp.Tag.Get("pad")
+// Populates a field skipped by its `if` condition with the constant from its
+// `default` tag.
+func (p *Parser) setDefault(fieldval reflect.Value, fieldtyp reflect.StructField, defkey string) error {
+	var err error
+	switch fieldval.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(defkey, 0, fieldval.Type().Bits()); err == nil {
+			fieldval.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(defkey, 0, fieldval.Type().Bits()); err == nil {
+			fieldval.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(defkey, fieldval.Type().Bits()); err == nil {
+			fieldval.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(defkey); err == nil {
+			fieldval.SetBool(b)
+		}
+	case reflect.String:
+		fieldval.SetString(defkey)
+	case reflect.Array:
+		b, ok := parseBytesLiteral(defkey)
+		if !ok || fieldval.Type().Elem().Kind() != reflect.Uint8 || len(b) != fieldval.Len() {
+			return p.errorf(ErrInvalidTag, nil, "Invalid value for `default` tag on '%v %v': %v. Expected %v bytes as a hex or quoted string.", fieldtyp.Name, fieldtyp.Type, defkey, fieldval.Len())
+		}
+		reflect.Copy(fieldval, reflect.ValueOf(b))
+	default:
+		return p.errorf(ErrUnsupportedType, nil, "Unable to apply `default` tag to '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+	}
+
+	if err != nil {
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `default` tag on '%v %v': %v.", fieldtyp.Name, fieldtyp.Type, defkey)
+	}
+	return nil
+}
+
+func (p *Parser) calculatePadding(fieldtyp reflect.StructField, offset uint64) (uint64, error) {
+	padstr := getTag(fieldtyp, "pad")
 	if len(padstr) > 0 {
 		padding, err := strconv.ParseUint(padstr, 0, 8)
 		if err != nil {
-			p.RaiseError2("Invalid value for `pad` tag: %v. Expected an integer.", padstr)
+			return 0, p.errorf(ErrInvalidTag, nil, "Invalid value for `pad` tag: %v. Expected an integer.", padstr)
 		}
 
 		nbytesRead := p.offset - offset
-		mod := nbytesRead % uint(padding)
+		mod := nbytesRead % padding
+		if mod != 0 {
+			return padding - mod, nil
+		}
+	}
+	return 0, nil
+}
+
+// Skips the padding after a field. With the PadCheck option, or when the
+// field has a `padcheck` tag naming the fill byte, as in `padcheck:"0xFF"`,
+// the padding has to consist of the fill byte only (zero by default).
+func (p *Parser) skipPadding(nbytes int, fieldtyp reflect.StructField) error {
+	checkstr := getTag(fieldtyp, "padcheck")
+	if !p.padcheck && len(checkstr) == 0 {
+		// Unchecked padding is still expected to be zeroed
+		start := p.offset
+		buf, err := p.readTemp(nbytes)
+		if err != nil {
+			return err
+		}
+		defer putBuf(buf)
+		for i, b := range buf {
+			if b != 0 {
+				p.warn(ErrAssertionFailed, "Nonzero padding after '%v %v' at offset %v: 0x%02x.", fieldtyp.Name, fieldtyp.Type, start+uint64(i), b)
+				break
+			}
+		}
+		return nil
+	}
+
+	var fill byte
+	if len(checkstr) > 0 {
+		n, err := strconv.ParseUint(checkstr, 0, 8)
+		if err != nil {
+			return p.errorf(ErrInvalidTag, nil, "Invalid value for `padcheck` tag: %v. Expected a byte value.", checkstr)
+		}
+		fill = byte(n)
+	}
+
+	start := p.offset
+	buf, err := p.readTemp(nbytes)
+	if err != nil {
+		return err
+	}
+	defer putBuf(buf)
+	for i, b := range buf {
+		if b != fill {
+			return p.fail(reflect.Value{}, ErrAssertionFailed, nil, "Invalid padding after '%v %v' at offset %v: 0x%02x. Expected 0x%02x.", fieldtyp.Name, fieldtyp.Type, start+uint64(i), b, fill)
+		}
+	}
+	return nil
+}
+
+// Unlike `pad`, which only looks at the bytes consumed by the field itself,
+// `align` aligns the parser's offset. The offset is taken relative to the
+// enclosing size-limited region, if any, or to the start of the stream.
+func (p *Parser) calculateAlignment(fieldtyp reflect.StructField) (uint64, error) {
+	alignstr := getTag(fieldtyp, "align")
+	if len(alignstr) > 0 {
+		alignment, err := strconv.ParseUint(alignstr, 0, 16)
+		if err != nil || alignment == 0 {
+			return 0, p.errorf(ErrInvalidTag, nil, "Invalid value for `align` tag: %v. Expected a positive integer.", alignstr)
+		}
+
+		mod := (p.offset - p.regionStart) % alignment
 		if mod != 0 {
-			return uint(padding) - mod
+			return alignment - mod, nil
 		}
 	}
-	return 0
+	return 0, nil
+}
+
+// Reports whether a tag that turns an option of a field on or off, as in
+// `optional:"true"`, is set and turns it on.
+func (p *Parser) flagTag(f *fieldPlan, tag string) (bool, error) {
+	value, ok := f.tags[tag]
+	if !ok {
+		return false, nil
+	}
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag on '%v %v': %v. Expected true or false.", tag, f.Name, f.Type, value)
+	}
+	return on, nil
+}
+
+// Reads a field tagged with `optional`, as in `optional:"true"`. Returns false
+// if the stream ends right where the field starts. Formats that grow new
+// sections at the end mark those optional so that older files still parse.
+func (p *Parser) readOptionalField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) (bool, error) {
+	start, depth, nframes := p.offset, p.depth, len(p.stack)
+	if err := p.readField(fieldval, fieldtyp, ptrval); err != nil {
+		if errors.Is(err, io.EOF) && p.offset == start {
+			p.depth, p.stack = depth, p.stack[:nframes]
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // Checks whether the given string refers to a field or a method on ptrval.
-func (p *Parser) parseRefTag(tag string, tagstr string, fieldtyp reflect.StructField, ptrval reflect.Value, index int) uint {
-	var value uint
-	var err error
+// Integer literals are accepted as well.
+func (p *Parser) parseRefTag(tag string, tagstr string, fieldtyp reflect.StructField, ptrval reflect.Value, index int) (uint64, error) {
+	if len(tagstr) == 0 {
+		return 0, p.errorf(ErrInvalidTag, nil, "Empty `%v` tag on '%v %v'. Expected a field, a method or an integer.", tag, fieldtyp.Name, fieldtyp.Type)
+	}
+	if c := tagstr[0]; '0' <= c && c <= '9' {
+		n, err := strconv.ParseUint(tagstr, 0, 64)
+		if err != nil {
+			return 0, p.errorf(ErrInvalidTag, nil, "Invalid value for `%v` tag: %v. Expected an integer.", tag, tagstr)
+		}
+		return n, nil
+	}
 
+	ptrval, tagstr, err := p.resolveAncestor(tag, tagstr, ptrval)
+	if err != nil {
+		return 0, err
+	}
+
+	var refval reflect.Value
 	strlen := len(tagstr)
 	if strlen > 2 && tagstr[strlen-2:] == "()" {
 		methodname := tagstr[:strlen-2]
-		if meth, ok := ptrval.Type().MethodByName(methodname); ok {
-			// TODO: check signature
-			ctxval := reflect.ValueOf(p)
-			var result reflect.Value
-			if index >= 0 {
-				indexval := reflect.ValueOf(index)
-				result = meth.Func.Call([]reflect.Value{ptrval, ctxval, indexval})[0]
-			} else {
-				result = meth.Func.Call([]reflect.Value{ptrval, ctxval})[0]
+		meth, ok := methodByName(ptrval.Type(), methodname)
+		if !ok {
+			return 0, p.errorf(ErrTagReference, nil, "Method '%v()' for '%v' not found. Referenced from a `%v` tag.", methodname, ptrval.Type(), tag)
+		}
+		// TODO: check signature
+		args := []reflect.Value{ptrval, reflect.ValueOf(p)}
+		if index >= 0 {
+			args = append(args, reflect.ValueOf(index))
+		}
+		results, err := p.callMethod(meth, args)
+		if err != nil {
+			return 0, err
+		}
+		refval = results[0]
+	} else if refval = fieldByPath(ptrval.Elem(), tagstr); refval.Kind() == reflect.Invalid {
+		return 0, p.errorf(ErrTagReference, nil, "Field '%v' for '%v %v' not found. Referenced from a `%v` tag.", tagstr, fieldtyp.Name, fieldtyp.Type, tag)
+	}
+
+	if err := p.checkNonNegative(refval, tag, tagstr, fieldtyp); err != nil {
+		return 0, err
+	}
+	value, ok := extractUint(refval)
+	if !ok {
+		return 0, p.errorf(ErrTagReference, nil, "Error trying to parse '%v' as an integer. Referenced from a `%v` tag in '%v'.", refval, tag, ptrval.Type())
+	}
+	return value, nil
+}
+
+// Strips the "^." prefixes from a reference, each of which refers to the
+// struct enclosing the one before, as in `len:"^.Header.EntrySize"`. Returns
+// the struct the rest of the reference applies to.
+func (p *Parser) resolveAncestor(tag, ref string, ptrval reflect.Value) (reflect.Value, string, error) {
+	level := 0
+	for strings.HasPrefix(ref, "^.") {
+		ref = ref[2:]
+		level++
+	}
+	if level == 0 {
+		return ptrval, ref, nil
+	}
+
+	idx := len(p.stack) - 1 - level
+	if idx < 0 {
+		return ptrval, ref, p.errorf(ErrTagReference, nil, "Reference '%v' in a `%v` tag in '%v' goes above the top-level struct.", strings.Repeat("^.", level)+ref, tag, ptrval.Type())
+	}
+	return p.stack[idx].ptrval, ref, nil
+}
+
+func (p *Parser) readSliceOfLength(fieldval reflect.Value, declared uint64, fieldtyp reflect.StructField, ptrval reflect.Value, elemsizekey string) error {
+	elemsize := fixedSize(fieldval.Type().Elem())
+	if elemsize > 0 {
+		if err := p.checkLength(declared, elemsize, fieldtyp); err != nil {
+			return err
+		}
+	}
+	length, err := p.intLength(declared, fieldtyp)
+	if err != nil {
+		return err
+	}
+	if fieldval.Type().Elem().Kind() == reflect.Uint8 {
+		if buf, ok := p.aliasInput(length); ok {
+			fieldval.SetBytes(buf)
+			return nil
+		}
+		if p.intern && length <= maxInterned {
+			buf := p.scratchBuf(length)
+			if err := p.EmitReadFull(buf); err != nil {
+				return err
 			}
-			value, err = p.extractUint(result)
+			fieldval.SetBytes(p.internBytes(buf))
+			return nil
+		}
+	}
+	factorykey := getTag(fieldtyp, "factory")
+	if elemsize > 0 && len(factorykey) == 0 && uint64(length)*uint64(elemsize) > readChunk && !p.inputHolds(uint64(length)*uint64(elemsize)) {
+		p.resetSlice(fieldval, length)
+		return p.readSliceInChunks(fieldval, length, elemsize)
+	}
+	// Make the slice in place, up front, so that elements read before a
+	// failure are kept. Growing a nil slice allocates its elements only.
+	p.resetSlice(fieldval, length)
+	fieldval.Grow(length)
+	fieldval.SetLen(length)
+	slice := fieldval
+	if len(factorykey) > 0 {
+		for i := 0; i < length; i++ {
+			elemptr, err := p.callFactory(factorykey, fieldtyp, ptrval, i)
 			if err != nil {
-				p.RaiseError2("Error trying to parse '%v' as an integer. Referenced from a `%v` tag in '%v'.", result, tag, ptrval.Type())
+				return err
+			}
+			p.elemIndex = i
+			err = p.readFieldOfLimitedSize("elemsize", elemsizekey, elemptr.Elem(), fieldtyp, ptrval, i)
+			p.elemIndex = -1
+			slice.Index(i).Set(elemptr)
+			if err != nil {
+				return err
 			}
-		} else {
-			p.RaiseError2("Method '%v()' for '%v' not found. Referenced from a `%v` tag.", methodname, ptrval.Type(), tag)
 		}
-	} else {
-		if fieldval := ptrval.Elem().FieldByName(tagstr); fieldval.Kind() != reflect.Invalid {
-			value, err = p.extractUint(fieldval)
+	} else if elemsize < 0 {
+		for i := 0; i < length; i++ {
+			elem := slice.Index(i)
+			p.elemIndex = i
+			err := p.readFieldOfLimitedSize("elemsize", elemsizekey, elem, fieldtyp, ptrval, i)
+			p.elemIndex = -1
 			if err != nil {
-				p.RaiseError2("Error trying to parse '%v' as an integer. Referenced from a `%v` tag in '%v'.", fieldval, tag, ptrval.Type())
+				return err
 			}
+		}
+	} else if elemtyp := fieldval.Type().Elem(); elemtyp.Kind() == reflect.Struct && p.canReadRaw(planFor(elemtyp)) {
+		return p.readRawSlice(slice)
+	} else if slice.Type().Elem().Kind() == reflect.Uint8 {
+		return p.EmitReadFull(slice.Bytes())
+	} else if isNumericElem(slice.Type().Elem()) {
+		return p.readNumericSlice(slice)
+	} else {
+		return p.EmitReadFixedFast(slice.Interface(), elemsize*length, fieldtyp, ptrval)
+	}
+	return nil
+}
+
+// Reads length fixed-size elements into a slice field a chunk at a time,
+// growing the slice as the data comes in, for lengths the input isn't known
+// to hold: a bogus length then fails at the end of the data rather than
+// allocating all it claims first. Elements read before a failure are kept,
+// though the offset stays at the start of the slice as with EmitReadFixed.
+func (p *Parser) readSliceInChunks(fieldval reflect.Value, length, elemsize int) error {
+	p.alignBits()
+	isBytes := fieldval.Type().Elem().Kind() == reflect.Uint8
+	step := max(numericChunk/elemsize, 1)
+	for done := 0; done < length; {
+		n := min(length-done, step)
+		fieldval.Grow(n)
+		fieldval.SetLen(done + n)
+		chunk := fieldval.Slice(done, done+n)
+		var buf []byte
+		if isBytes {
+			buf = chunk.Bytes()
 		} else {
-			p.RaiseError2("Field '%v' for '%v %v' not found. Referenced from a `%v` tag.", tagstr, fieldtyp.Name, fieldtyp.Type, tag)
+			buf = p.scratchBuf(n * elemsize)
+		}
+		got, err := io.ReadFull(p.r, buf)
+		if m := got / elemsize; m > 0 && !isBytes && !p.copyNative(chunk.Slice(0, m), buf[:m*elemsize]) {
+			if _, derr := binary.Decode(buf[:m*elemsize], p.byteOrder, chunk.Slice(0, m).Interface()); derr != nil {
+				return p.wrapError(derr)
+			}
+		}
+		if err != nil {
+			fieldval.SetLen(done + got/elemsize)
+			if err == io.EOF && done > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return p.shortReadError(err, int64(length)*int64(elemsize), int64(done*elemsize+got))
 		}
+		done += n
 	}
-	return value
+	p.offset += uint64(length) * uint64(elemsize)
+	return nil
 }
 
-func (p *Parser) readSliceOfLength(fieldval reflect.Value, length int, fieldtyp reflect.StructField, ptrval reflect.Value, elemsizekey string) {
-	slice := reflect.MakeSlice(fieldval.Type(), length, length)
-	islice := slice.Interface()
-	if size := binary.Size(islice); size < 0 {
-		for i := 0; i < length; i++ {
-			elem := slice.Index(i)
-			p.readFieldOfLimitedSize("elemsize", elemsizekey, elem, fieldtyp, ptrval, i)
+// Empties a slice field before length elements are read into it. With the
+// ReuseSlices option, meant for structs that are parsed into over and over,
+// such as one per frame, the slice keeps its backing array, with the elements
+// zeroed so that none of the previous contents leak into fields that aren't
+// read. Otherwise the field is set to nil, leaving whatever the slice was
+// shared with alone.
+func (p *Parser) resetSlice(fieldval reflect.Value, length int) {
+	if p.reuseSlices {
+		fieldval.SetLen(min(length, fieldval.Cap()))
+		fieldval.Clear()
+		fieldval.SetLen(0)
+		return
+	}
+	fieldval.SetZero()
+}
+
+// Reads the length of a field from the stream if it has a `lenprefix` tag, or
+// works it out from its `len` tag. Returns false if it has neither.
+func (p *Parser) fieldLength(fieldtyp reflect.StructField, ptrval reflect.Value) (uint64, bool, error) {
+	if prefixkey := getTag(fieldtyp, "lenprefix"); len(prefixkey) > 0 {
+		length, err := p.readLengthPrefix(prefixkey, fieldtyp, ptrval)
+		return length, true, err
+	}
+	if lenkey := getTag(fieldtyp, "len"); len(lenkey) > 0 {
+		length, err := p.parseLengthTag("len", lenkey, fieldtyp, ptrval, -1)
+		return length, true, err
+	}
+	return 0, false, nil
+}
+
+// Reads a string whose length in bytes is given by a `len` or a `lenprefix`
+// tag, or which ends with the sequence given by a `terminator` tag.
+func (p *Parser) readString(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	if termkey := getTag(fieldtyp, "terminator"); len(termkey) > 0 {
+		term, err := p.parseTerminator(termkey)
+		if err != nil {
+			return err
 		}
-	} else {
-		p.EmitReadFixed(islice, fieldtyp, ptrval)
+		buf, err := p.readUntil(term)
+		if err != nil {
+			return err
+		}
+		fieldval.SetString(p.internString(buf))
+		return nil
+	}
+
+	length, ok, err := p.fieldLength(fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+	}
+	if err := p.checkLength(length, 1, fieldtyp); err != nil {
+		return err
+	}
+	n, err := p.intLength(length, fieldtyp)
+	if err != nil {
+		return err
+	}
+	buf, err := p.readTemp(n)
+	if err != nil {
+		return err
+	}
+	fieldval.SetString(p.internString(buf))
+	putBuf(buf)
+	return nil
+}
+
+// Maps are made with room for at most this many entries and grow from there,
+// so that a bogus length doesn't allocate more than the entries there are
+const mapSizeHint = 1024
+
+// Reads length key/value pairs into a new map. Each entry is stored as the key
+// immediately followed by its value.
+func (p *Parser) readMapOfLength(fieldval reflect.Value, length int, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	maptyp := fieldval.Type()
+	m := reflect.MakeMapWithSize(maptyp, min(length, mapSizeHint))
+	fieldval.Set(m)
+	for i := 0; i < length; i++ {
+		key := reflect.New(maptyp.Key()).Elem()
+		if err := p.readElem(key, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		elem := reflect.New(maptyp.Elem()).Elem()
+		if err := p.readElem(elem, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// Reads a single value that isn't a field of its own, such as a map key.
+func (p *Parser) readElem(val reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	if val.Kind() == reflect.Struct {
+		return p.emitReadStruct(buildPtr(val))
+	}
+	ok, err := p.EmitReadFixed(buildPtr(val), fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type %v not supported.", fieldtyp.Name, fieldtyp.Type, val.Type())
+	}
+	return nil
+}
+
+var storageTypes = map[string]reflect.Type{
+	"int8":   reflect.TypeOf(int8(0)),
+	"int16":  reflect.TypeOf(int16(0)),
+	"int32":  reflect.TypeOf(int32(0)),
+	"int64":  reflect.TypeOf(int64(0)),
+	"uint8":  reflect.TypeOf(uint8(0)),
+	"uint16": reflect.TypeOf(uint16(0)),
+	"uint32": reflect.TypeOf(uint32(0)),
+	"uint64": reflect.TypeOf(uint64(0)),
+}
+
+// Reads an integer of the named type for tags that let the stored
+// representation differ from the type of the field.
+func (p *Parser) readStorage(tag, storage string, fieldtyp reflect.StructField, ptrval reflect.Value) (reflect.Value, error) {
+	typ, ok := storageTypes[storage]
+	if !ok {
+		return reflect.Value{}, p.errorf(ErrInvalidTag, nil, "Invalid storage type in `%v` tag: %v. Expected a fixed-size integer type.", tag, storage)
 	}
-	fieldval.Set(slice)
+	rawptr := reflect.New(typ)
+	if err := p.EmitReadFixedFast(rawptr.Interface(), int(typ.Size()), fieldtyp, ptrval); err != nil {
+		return reflect.Value{}, err
+	}
+	return rawptr.Elem(), nil
 }
 
-func (p *Parser) EmitReadFixed(data interface{}, fieldtyp reflect.StructField, ptrval reflect.Value) bool {
+// EmitReadFixed reads a value of a fixed size, as with binary.Read, into data.
+// It returns false if data doesn't have a fixed size.
+func (p *Parser) EmitReadFixed(data interface{}, fieldtyp reflect.StructField, ptrval reflect.Value) (bool, error) {
 	size := binary.Size(data)
 	if size < 0 {
-		return false
+		return false, nil
 	}
 
-	p.EmitReadFixedFast(data, size, fieldtyp, ptrval)
-	return true
+	return true, p.EmitReadFixedFast(data, size, fieldtyp, ptrval)
 }
 
-func (p *Parser) EmitReadFixedFast(data interface{}, size int, fieldtyp reflect.StructField, ptrval reflect.Value) {
-	err := binary.Read(p.r, p.byteOrder, data)
-	if err != nil {
-		p.RaiseError2("%v while reading %v bytes into '%v %v' of %v", err, size, fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type())
+// EmitReadFixedFast is like EmitReadFixed for when the size of data is known.
+func (p *Parser) EmitReadFixedFast(data interface{}, size int, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	buf := p.scratchBuf(size)
+	if n, err := io.ReadFull(p.r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return p.errorf(ErrUnexpectedEOF, err, "Unexpected end of data while reading '%v %v' of %v at offset %v: expected %v bytes, got %v.", fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type(), p.offset, size, n)
+		}
+		return p.errorf(nil, err, "%v while reading %v bytes into '%v %v' of %v", err, size, fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type())
 	}
-	p.offset += uint(size)
+	val := reflect.ValueOf(data)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if p.copyNative(val, buf) {
+		p.offset += uint64(size)
+		return nil
+	}
+	if _, err := binary.Decode(buf, p.byteOrder, data); err != nil {
+		return p.errorf(nil, err, "%v while reading %v bytes into '%v %v' of %v", err, size, fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type())
+	}
+	p.offset += uint64(size)
+	return nil
 }
 
-func (p *Parser) EmitReadNBytes(nbytes int) []byte {
-	buf := make([]byte, nbytes)
-	p.EmitReadFull(buf)
-	return buf
+// Reads larger than this get a buffer that grows as the data comes in, so
+// that a bogus length doesn't allocate more than the data there is
+const readChunk = 1 << 20
+
+// EmitReadNBytes reads the next nbytes bytes of the stream.
+func (p *Parser) EmitReadNBytes(nbytes int) ([]byte, error) {
+	if nbytes <= readChunk {
+		buf := make([]byte, nbytes)
+		if err := p.EmitReadFull(buf); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	buf := make([]byte, 0, readChunk)
+	for len(buf) < nbytes {
+		if len(buf) == cap(buf) {
+			buf = append(make([]byte, 0, min(2*cap(buf), nbytes)), buf...)
+		}
+		n, err := io.ReadFull(p.r, buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			return nil, p.shortReadError(err, int64(nbytes), int64(len(buf)))
+		}
+	}
+	p.offset += uint64(nbytes)
+	return buf, nil
 }
 
-func (p *Parser) EmitReadFull(buf []byte) {
+// EmitReadFull fills buf from the stream.
+func (p *Parser) EmitReadFull(buf []byte) error {
 	nbytes, err := io.ReadFull(p.r, buf)
 	if err != nil {
-		p.RaiseError(err)
+		return p.shortReadError(err, int64(len(buf)), int64(nbytes))
+	}
+	p.offset += uint64(nbytes)
+	return nil
+}
+
+// Describes a read of want bytes at the current offset that only got some of
+// them, naming the field being read.
+func (p *Parser) shortReadError(err error, want, got int64) error {
+	if n := len(p.stack); n > 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+		f := p.stack[n-1]
+		return p.errorf(ErrUnexpectedEOF, err, "Unexpected end of data while reading '%v %v' of %v at offset %v: expected %v bytes, got %v.", f.field, f.fieldType, f.ptrval.Elem().Type(), p.offset, want, got)
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// read outside of any struct, as by ReadUint32
+		return p.errorf(ErrUnexpectedEOF, err, "Unexpected end of data at offset %v: expected %v bytes, got %v.", p.offset, want, got)
 	}
-	p.offset += uint(nbytes)
+	return p.wrapError(err)
 }
 
-func (p *Parser) EmitReadAll() []byte {
+// EmitReadAll reads the rest of the stream.
+func (p *Parser) EmitReadAll() ([]byte, error) {
 	var buf bytes.Buffer
 	nbytes, err := buf.ReadFrom(p.r)
 	if err != nil {
-		p.RaiseError(err)
+		return nil, p.wrapError(err)
 	}
-	p.offset += uint(nbytes)
-	return buf.Bytes()
+	p.offset += uint64(nbytes)
+	return buf.Bytes(), nil
 }
 
-func (p *Parser) EmitSkipNBytes(nbytes int) {
-	// FIXME: remove unbounded allocation
-	p.EmitReadNBytes(nbytes)
+// EmitSkipNBytes consumes the next nbytes bytes of the stream. Readers that
+// implement io.Seeker are seeked past them, as long as the stream holds that
+// many bytes; others are read through without keeping the bytes around.
+func (p *Parser) EmitSkipNBytes(nbytes int) error {
+	return p.skipBytes(uint64(nbytes))
 }
 
-func (p *Parser) readFieldOfLimitedSize(tag, tagstr string, val reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, index int) {
+// Skips shorter than this are read through even if the reader can seek, as
+// checking that the stream holds them takes three calls to Seek and drops what
+// the parser has buffered.
+const seekSkipMin = 64 << 10
+
+// Like EmitSkipNBytes, for any number of bytes the stream may hold.
+func (p *Parser) skipBytes(count uint64) error {
+	if count > math.MaxInt64 {
+		return p.errorf(ErrInvalidLength, nil, "Unable to skip %v bytes at offset %v.", count, p.offset)
+	}
+	nbytes := int64(count)
+	if nbytes >= seekSkipMin {
+		if left, ok := seekableLeft(p.r); ok && left >= nbytes {
+			if err := seekReader(p.r, nbytes); err != nil {
+				return p.wrapError(err)
+			}
+			p.offset += uint64(nbytes)
+			return nil
+		}
+	}
+	n, err := io.CopyN(io.Discard, p.r, nbytes)
+	if err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return p.shortReadError(err, nbytes, n)
+	}
+	p.offset += uint64(n)
+	return nil
+}
+
+// Returns a reader of the next n bytes of r for a size-limited region,
+// reusing one from an earlier region if there is one. The readers of the
+// regions ended since mark was taken from nlimits are given back with
+// popLimits(mark).
+func (p *Parser) pushLimit(r io.Reader, n int64) *io.LimitedReader {
+	if p.nlimits == len(p.limiters) {
+		p.limiters = append(p.limiters, new(io.LimitedReader))
+	}
+	limit_r := p.limiters[p.nlimits]
+	limit_r.R, limit_r.N = r, n
+	p.nlimits++
+	return limit_r
+}
+
+func (p *Parser) popLimits(mark int) {
+	for ; p.nlimits > mark; p.nlimits-- {
+		p.limiters[p.nlimits-1].R = nil
+	}
+}
+
+func (p *Parser) readFieldOfLimitedSize(tag, tagstr string, val reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, index int) error {
 	if len(tagstr) == 0 {
-		p.emitReadStruct(buildPtr(val))
-		return
+		return p.emitReadStruct(buildPtr(val))
 	}
 
 	var (
-		tmp_r   io.Reader
-		limit_r io.LimitedReader
-		size    int
+		size uint64
+		err  error
 	)
 
 	if tagstr == "<inf>" {
-		p.RaiseError2("Invalid `%v` tag value while parsing '%v %v'. Can only use \"<inf>\" with slices.", tag, fieldtyp.Name, fieldtyp.Type)
+		return p.errorf(ErrInvalidTag, nil, "Invalid `%v` tag value while parsing '%v %v'. Can only use \"<inf>\" with slices.", tag, fieldtyp.Name, fieldtyp.Type)
 	}
 
-	size = int(p.parseRefTag(tag, tagstr, fieldtyp, ptrval, index))
+	if tagstr == "<rest>" {
+		if size, err = p.remainingInRegion(tag, fieldtyp); err != nil {
+			return err
+		}
+	} else {
+		n, err := p.parseLengthTag(tag, tagstr, fieldtyp, ptrval, index)
+		if err != nil {
+			return err
+		}
+		size = n
+		if err := p.checkLength(size, 1, fieldtyp); err != nil {
+			return err
+		}
+	}
 	if size == 0 {
-		return
+		return nil
+	}
+	if size > math.MaxInt64 {
+		return p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Size %v is too large.", fieldtyp.Name, fieldtyp.Type, size)
 	}
 
-	tmp_r, limit_r = p.r, io.LimitedReader{p.r, int64(size)}
-	p.r = &limit_r
+	tmp_r, tmp_start, mark := p.r, p.regionStart, p.nlimits
+	limit_r := p.pushLimit(p.r, int64(size))
+	p.r, p.regionStart = limit_r, p.offset
 
-	p.emitReadStruct(buildPtr(val))
+	if p.collect {
+		err = p.readRegionCollecting(val, limit_r)
+	} else {
+		err = p.emitReadStruct(buildPtr(val))
+	}
+	if err != nil {
+		return err
+	}
 
 	if limit_r.N != 0 {
-		p.RaiseError2("Error reading exactly %v bytes into '%v %v' of %v. Actual bytes read: %v", size, fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type(), int64(size)-limit_r.N)
+		if err := p.fail(reflect.Value{}, ErrSizeMismatch, nil, "Error reading exactly %v bytes into '%v %v' of %v. Actual bytes read: %v", size, fieldtyp.Name, fieldtyp.Type, ptrval.Elem().Type(), int64(size)-limit_r.N); err != nil {
+			return err
+		}
+		if err := p.skipBytes(uint64(limit_r.N)); err != nil {
+			return err
+		}
 	}
-	p.r = tmp_r
+	p.r, p.regionStart = tmp_r, tmp_start
+	p.popLimits(mark)
+	return nil
 }
 
-func (p *Parser) readSliceFromBytes(val reflect.Value, typ reflect.Type, buf []byte) {
+// Returns the number of bytes left in the innermost size-limited region, for
+// `size:"<rest>"`.
+func (p *Parser) remainingInRegion(tag string, fieldtyp reflect.StructField) (uint64, error) {
+	if p.r != p.src {
+		switch r := p.r.(type) {
+		case *io.LimitedReader:
+			return uint64(r.N), nil
+		case *bytes.Reader:
+			// elements of a slice read from a buffered region
+			return uint64(r.Len()), nil
+		}
+	}
+	return 0, p.errorf(ErrInvalidTag, nil, "Invalid `%v` tag value while parsing '%v %v'. Can only use \"<rest>\" inside a size-limited region.", tag, fieldtyp.Name, fieldtyp.Type)
+}
+
+func (p *Parser) readSliceFromBytes(val reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, buf []byte) error {
 	// Fast path for []byte
 	if _, ok := val.Interface().([]byte); ok {
-		val.Set(reflect.ValueOf(buf))
-		return
+		val.Set(reflect.ValueOf(p.internBytes(buf)))
+		return nil
 	}
 
-	// Create a temporary reader just for this function
-	tmp_reader, tmp_offset := p.r, p.offset
-	p.r = bytes.NewReader(buf)
+	// Create a temporary reader just for this function. The bytes have been
+	// read, so the region starts len(buf) bytes back.
+	tmp_reader, tmp_offset, tmp_start, tmp_pos := p.r, p.offset, p.regionStart, p.copyPos
+	copyPos, ok := p.readerPos()
+	if !ok {
+		copyPos = -1
+	} else {
+		copyPos -= int64(len(buf))
+	}
+	p.offset -= uint64(len(buf))
+	p.r, p.regionStart, p.copyPos = bytes.NewReader(buf), p.offset, copyPos
+	if err := p.readElemsOfSize(val, uint64(len(buf)), fieldtyp, ptrval); err != nil {
+		return err
+	}
 
-	size := uint(len(buf))
-	sliceval := val
-	bytesRead := uint(0)
-	for bytesRead < size {
-		offset := p.offset
-		elemptr := reflect.New(typ.Elem())
+	// Restore parser's state
+	p.r, p.offset, p.regionStart, p.copyPos = tmp_reader, tmp_offset, tmp_start, tmp_pos
+	return nil
+}
 
-		p.emitReadStruct(elemptr.Interface())
-		sliceval = reflect.Append(sliceval, elemptr.Elem())
+// Reads a slice whose contents take up the next size bytes of the stream,
+// parsing the elements as they're read.
+func (p *Parser) readSliceOfSize(val reflect.Value, size uint64, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	if size == 0 {
+		return nil
+	}
+	if size > math.MaxInt64 {
+		return p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Size %v is too large.", fieldtyp.Name, fieldtyp.Type, size)
+	}
+	tmp_r, tmp_start, mark := p.r, p.regionStart, p.nlimits
+	p.r, p.regionStart = p.pushLimit(p.r, int64(size)), p.offset
+	if err := p.readElemsOfSize(val, size, fieldtyp, ptrval); err != nil {
+		return err
+	}
+	p.r, p.regionStart = tmp_r, tmp_start
+	p.popLimits(mark)
+	return nil
+}
 
-		bytesRead += uint(p.offset - offset)
+// Parses elements from the parser's reader and appends them to the slice
+// val until they have taken up size bytes.
+func (p *Parser) readElemsOfSize(val reflect.Value, size uint64, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	factorykey := getTag(fieldtyp, "factory")
+	sliceval := val
+	if p.reuseSlices {
+		sliceval = val.Slice(0, 0)
 	}
-	if bytesRead != size {
-		p.RaiseError(errors.New("Consistency error: mismatch between block size and total size of elements contained in it"))
+	start := p.offset
+	for p.offset-start < size {
+		var elemptr reflect.Value
+		if len(factorykey) > 0 {
+			var err error
+			if elemptr, err = p.callFactory(factorykey, fieldtyp, ptrval, sliceval.Len()); err != nil {
+				return err
+			}
+		} else {
+			elemptr = reflect.New(fieldtyp.Type.Elem())
+		}
+		p.elemIndex = sliceval.Len()
+		if err := p.emitReadStruct(elemptr.Interface()); err != nil {
+			return err
+		}
+		if len(factorykey) > 0 {
+			sliceval = reflect.Append(sliceval, elemptr)
+		} else {
+			sliceval = reflect.Append(sliceval, elemptr.Elem())
+		}
+	}
+	if p.offset-start != size {
+		return p.errorf(ErrSizeMismatch, nil, "Consistency error: mismatch between block size and total size of elements contained in it")
 	}
 	// Assign the newly allocated slice to the original field
 	val.Set(sliceval)
-
-	// Restore parser's state
-	p.r, p.offset = tmp_reader, tmp_offset
+	return nil
 }
 
+// RaiseError aborts the parse with err. It panics, so it may only be called
+// from within hooks and custom parsers, where the panic is turned back into
+// the error EmitReadStruct returns.
+//
+// Deprecated: Return the error from the hook instead.
 func (p *Parser) RaiseError(err error) {
-	panic(err)
+	panic(p.wrapError(err))
 }
 
+// RaiseError2 is like RaiseError with a formatted message.
+//
+// Deprecated: Return the error from the hook instead.
 func (p *Parser) RaiseError2(msg string, args ...interface{}) {
-	panic(parseError(fmt.Sprintf(msg, args...)))
+	panic(p.parseError(nil, nil, fmt.Sprintf(msg, args...)))
+}
+
+// Creates an error located at the field the parser is currently reading,
+// marked with one of the Err sentinels and the error that caused it, if any.
+func (p *Parser) errorf(kind, cause error, msg string, args ...interface{}) error {
+	return p.parseError(kind, cause, fmt.Sprintf(msg, args...))
+}
+
+// Wraps an error from the reader or from user code in a ParseError, unless it
+// already is one.
+func (p *Parser) wrapError(err error) error {
+	if _, ok := err.(*ParseError); ok {
+		return err
+	}
+	return p.parseError(nil, err, err.Error())
+}
+
+// Signed values referenced from tags are converted to uint, so a negative one
+// has to be caught before it turns into a huge length.
+func (p *Parser) checkNonNegative(val reflect.Value, tag, ref string, fieldtyp reflect.StructField) error {
+	if val.CanInt() && val.Int() < 0 {
+		return p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Negative value %v for `%v` tag from '%v'.", fieldtyp.Name, fieldtyp.Type, val.Int(), tag, ref)
+	}
+	return nil
 }
 
-func (p *Parser) extractUint(val reflect.Value) (uint, error) {
+func extractUint(val reflect.Value) (uint64, bool) {
 	switch val.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return uint(val.Int()), nil
+		return uint64(val.Int()), true
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return uint(val.Uint()), nil
+		return val.Uint(), true
 	}
-	return 0, errors.New("")
+	return 0, false
 }
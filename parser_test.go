@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
 	"testing"
 	"unicode/utf16"
 )
@@ -430,7 +434,7 @@ func TestSliceInt(t *testing.T) {
 	if !(uint32(len(s.Data)) == s.Length && isEqualu16(s.Data, []uint16{1, 2, 3, 4})) {
 		t.Error("Invalid data read into Data []byte:", s.Data)
 	}
-	if p.offset != uint(len(data)) {
+	if p.offset != uint64(len(data)) {
 		t.Error("Invalid offset:", p.offset)
 	}
 }
@@ -812,6 +816,34 @@ func TestCustomSlice(t *testing.T) {
 	}
 }
 
+func TestSizedSliceStreamed(t *testing.T) {
+	type VarStruct struct {
+		DataLength uint8
+		Data       []byte `len:"DataLength"`
+	}
+	s := struct {
+		Size  uint32
+		Slice []VarStruct `size:"Size"`
+	}{}
+	data := []byte{6, 0, 0, 0, 2, 'a', 'b', 2, 'c', 'd'}
+	p := NewParserBytes(data, LittleEndian, AliasInput)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Slice) != 2 || &s.Slice[1].Data[0] != &data[8] {
+		t.Error("Expected the elements to be parsed from the input:", s.Slice)
+	}
+
+	// The elements are parsed before the whole slice has been read
+	data = []byte{0, 0, 0, 0x10, 2, 'a', 'b', 2, 'c'}
+	p = NewParser(io.MultiReader(bytes.NewReader(data)), LittleEndian, Default)
+	err := p.EmitReadStruct(&s)
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrUnexpectedEOF) || perr.Offset != 8 {
+		t.Error("Incorrect error:", err)
+	}
+}
+
 type DescriptorT struct {
 	ClassIDString UnicodeString
 	ClassID       [4]byte `if:"ShouldParseClassID"`
@@ -1061,7 +1093,7 @@ func TestReadUntilEOF(t *testing.T) {
 	if string(s.Data) != "Hello world!" {
 		t.Error("Error reading until EOF into []byte:", s.Data)
 	}
-	if p.offset != uint(len(data)) {
+	if p.offset != uint64(len(data)) {
 		t.Error("Invalid offset after reading until EOF into []byte:", p.offset)
 	}
 }
@@ -1070,10 +1102,10 @@ type MissingVerifier struct {
 	Length uint32 `after:"Verify"`
 }
 
-// FIXME:
-//func (m *MissingVerifier) Verify() bool {
-//	return false
-//}
+// Wrong signature, so it doesn't count
+func (m *MissingVerifier) Verify() bool {
+	return false
+}
 
 func TestMissingVerify(t *testing.T) {
 	s := MissingVerifier{}
@@ -1092,6 +1124,50 @@ func TestMissingVerify(t *testing.T) {
 	}
 }
 
+type NoResultVerifier struct {
+	Length uint32 `after:"Verify"`
+}
+
+func (v *NoResultVerifier) Verify(p *Parser) {}
+
+type WrongArgVerifier struct {
+	Length uint32 `after:"Verify"`
+}
+
+func (v *WrongArgVerifier) Verify(length uint32) error {
+	return nil
+}
+
+type WrongIndexVerifier struct {
+	Length uint32 `after:"Verify"`
+}
+
+func (v *WrongIndexVerifier) Verify(p *Parser, index string) error {
+	return nil
+}
+
+func TestVerifySignature(t *testing.T) {
+	tests := []struct {
+		data    interface{}
+		message string
+	}{
+		{&NoResultVerifier{}, "Proper 'Verify' method not found on the type *bingo.NoResultVerifier."},
+		{&WrongArgVerifier{}, "Proper 'Verify' method not found on the type *bingo.WrongArgVerifier."},
+		{&WrongIndexVerifier{}, "Proper 'Verify' method not found on the type *bingo.WrongIndexVerifier."},
+	}
+	for _, test := range tests {
+		p := newParser()
+
+		if err := p.EmitReadStruct(test.data); err != nil {
+			if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrTagReference) || perr.Error() != test.message {
+				t.Error("Incorrect error:", err)
+			}
+		} else {
+			t.Error("Expected an error for", test.data)
+		}
+	}
+}
+
 type FailingVerifier struct {
 	Length uint32 `after:"VerifyLength"`
 }
@@ -1201,9 +1277,644 @@ func TestStrictMode(t *testing.T) {
 	}
 }
 
+type MapStruct struct {
+	Count   uint8
+	Strings map[uint32]UnicodeString `len:"Count"`
+}
+
+func TestMapField(t *testing.T) {
+	data := []byte{2,
+		7, 0, 0, 0, // key
+		1, 0, 0, 0, 'a', 0, // value
+		9, 0, 0, 0, // key
+		2, 0, 0, 0, 'b', 0, 'c', 0} // value
+	s := MapStruct{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Strings) != 2 {
+		t.Fatal("Invalid map length:", len(s.Strings))
+	}
+	if string(utf16.Decode(s.Strings[7].Chars)) != "a" {
+		t.Error("Error parsing map entry 7:", s.Strings[7])
+	}
+	if string(utf16.Decode(s.Strings[9].Chars)) != "bc" {
+		t.Error("Error parsing map entry 9:", s.Strings[9])
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestMapFieldWithoutLen(t *testing.T) {
+	s := struct {
+		Strings map[uint32]uint32
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Strings map[uint32]uint32'. Map fields require a `len` tag." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 0 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type EntryTable struct {
+	Header struct {
+		Count     uint8
+		EntrySize uint8
+	}
+	Entries []TableEntry `len:"Header.Count"`
+}
+
+type TableEntry struct {
+	Kind uint8
+	Data []byte `len:"^.Header.EntrySize"`
+	Wide uint16 `if:"Kind == ^.Header.EntrySize"`
+}
+
+func TestParentReference(t *testing.T) {
+	data := []byte{2, 2,
+		1, 'a', 'b',
+		2, 'c', 'd', 0x34, 0x12}
+	s := EntryTable{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Entries) != 2 {
+		t.Fatal("Invalid slice length:", len(s.Entries))
+	}
+	if !(string(s.Entries[0].Data) == "ab" && s.Entries[0].Wide == 0) {
+		t.Error("Error parsing first entry:", s.Entries[0])
+	}
+	if !(string(s.Entries[1].Data) == "cd" && s.Entries[1].Wide == 0x1234) {
+		t.Error("Error parsing second entry:", s.Entries[1])
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestParentReferenceAboveTop(t *testing.T) {
+	s := TableEntry{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Reference '^.Header.EntrySize' in a `len` tag in '*bingo.TableEntry' goes above the top-level struct." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestSkipTag(t *testing.T) {
+	data := []byte{1, 0xEE, 0xEE, 0xEE, 2, 3, 0xEE, 0xEE, 0xEE, 0xEE, 4}
+	s := struct {
+		First    uint8
+		_        Skip `skip:"3"`
+		Second   uint8
+		Reserved uint8
+		Third    uint8
+		Marker   [4]byte `skip:"Reserved"`
+		Fourth   uint8
+	}{}
+	p := NewParser(bytes.NewReader(data), LittleEndian, Strict)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.First == 1 && s.Second == 2 && s.Reserved == 3 && s.Third == 0xEE && s.Fourth == 4) {
+		t.Error("Error skipping reserved bytes:", s)
+	}
+	if s.Marker != [4]byte{} {
+		t.Error("Skip marker field was modified:", s.Marker)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestLiteralLenTag(t *testing.T) {
+	s := struct {
+		Data []byte `len:"3"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Data) != 3 {
+		t.Error("Error parsing slice with literal length:", s.Data)
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type AlignedChunk struct {
+	Size uint8
+	Data []byte `len:"Size" align:"4"`
+}
+
+func TestAlignTag(t *testing.T) {
+	data := []byte{0xAA,
+		1, 'a', 0, // aligned to absolute offset 4
+		4,
+		2, 'b', 'c', 0, // aligned to offset 4 within the region starting at 5
+		5}
+	s := struct {
+		First  uint8
+		Chunk  AlignedChunk
+		Size   uint8
+		Nested AlignedChunk `size:"Size"`
+		Last   uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if string(s.Chunk.Data) != "a" {
+		t.Error("Error parsing aligned chunk:", s.Chunk)
+	}
+	if string(s.Nested.Data) != "bc" {
+		t.Error("Error parsing aligned chunk in sized region:", s.Nested)
+	}
+	if s.Last != 5 {
+		t.Error("Error parsing field after aligned chunks:", s.Last)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type RestChunk struct {
+	Kind    uint8
+	Payload []byte `size:"<rest>"`
+}
+
+func TestRestSizeTag(t *testing.T) {
+	data := []byte{4, 1, 'a', 'b', 'c',
+		3, 2, 'd', 'e',
+		0xEE}
+	s := struct {
+		Size    uint8
+		First   RestChunk `size:"Size"`
+		Size2   uint8
+		Second  RestChunk `size:"Size2"`
+		Trailer uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.First.Kind == 1 && string(s.First.Payload) == "abc") {
+		t.Error("Error parsing rest of first chunk:", s.First)
+	}
+	if !(s.Second.Kind == 2 && string(s.Second.Payload) == "de") {
+		t.Error("Error parsing rest of second chunk:", s.Second)
+	}
+	if s.Trailer != 0xEE {
+		t.Error("Error parsing field after chunks:", s.Trailer)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestRestSizeTagOutsideRegion(t *testing.T) {
+	s := RestChunk{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid `size` tag value while parsing 'Payload []uint8'. Can only use \"<rest>\" inside a size-limited region." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestPadCheck(t *testing.T) {
+	data := []byte{1, 0, 0, 0, 2, 0xFF, 3, 1, 4, 0}
+	s := struct {
+		A uint8 `pad:"4"`
+		B uint8 `pad:"2" padcheck:"0xFF"`
+		C uint8 `align:"4"`
+		D uint8 `pad:"2"`
+	}{}
+	p := NewParser(bytes.NewReader(data), LittleEndian, PadCheck)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid padding after 'C uint8' at offset 7: 0x01. Expected 0x00." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if s.A != 1 || s.B != 2 || s.C != 3 {
+		t.Error("Error parsing padded fields:", s)
+	}
+	if p.offset != 8 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestPadCheckDisabled(t *testing.T) {
+	data := []byte{1, 0xAA, 2, 0xFF}
+	s := struct {
+		A uint8 `pad:"2"`
+		B uint8 `pad:"2" padcheck:"0xFF"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if p.offset != 4 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOptionalFields(t *testing.T) {
+	type Header struct {
+		Version uint8
+		Flags   uint16 `optional:"true"`
+		Ext     struct {
+			Size uint8
+			Data []byte `len:"Size"`
+		} `optional:"true"`
+	}
+
+	tests := []struct {
+		data    []byte
+		flags   uint16
+		extSize uint8
+	}{
+		{[]byte{1}, 0, 0},
+		{[]byte{1, 2, 0}, 2, 0},
+		{[]byte{1, 2, 0, 1, 'x'}, 2, 1},
+	}
+	for _, test := range tests {
+		s := Header{}
+		p := newParserData(test.data)
+
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Error(err)
+		}
+
+		if s.Version != 1 || s.Flags != test.flags || s.Ext.Size != test.extSize {
+			t.Error("Error parsing optional fields:", s)
+		}
+		if p.offset != uint64(len(test.data)) {
+			t.Error("Invalid offset:", p.offset)
+		}
+	}
+}
+
+func TestOptionalFieldTruncated(t *testing.T) {
+	s := struct {
+		Version uint8
+		Flags   uint16 `optional:"true"`
+	}{}
+	p := newParserData([]byte{1, 2})
+
+	if err := p.EmitReadStruct(&s); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+func TestOptionalTagValues(t *testing.T) {
+	off := struct {
+		Version uint8
+		Flags   uint16 `optional:"false"`
+	}{}
+	if err := newParserData([]byte{1}).EmitReadStruct(&off); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Expected `optional:\"false\"` to leave the field required. Got", err)
+	}
+
+	bare := struct {
+		Version uint8
+		Flags   uint16 `bingo:"optional"`
+	}{}
+	if err := newParserData([]byte{1}).EmitReadStruct(&bare); err != nil {
+		t.Error("Expected `bingo:\"optional\"` to make the field optional. Got", err)
+	}
+
+	invalid := struct {
+		Version uint8
+		Flags   uint16 `optional:"maybe"`
+	}{}
+	if err := newParserData([]byte{1}).EmitReadStruct(&invalid); !errors.Is(err, ErrInvalidTag) || err.Error() != "Invalid value for `optional` tag on 'Flags uint16': maybe. Expected true or false." {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+type BigEndianChunk struct {
+	Length uint16
+	Inner  struct {
+		Value uint32
+	}
+}
+
+func (c *BigEndianChunk) ByteOrder() ByteOrder {
+	return BigEndian
+}
+
+func TestStructByteOrder(t *testing.T) {
+	data := []byte{0x01, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x04, 0x00}
+	s := struct {
+		Header uint16
+		Chunk  BigEndianChunk
+		After  uint16
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Header != 1 || s.After != 4 {
+		t.Error("Error parsing little-endian fields:", s.Header, s.After)
+	}
+	if s.Chunk.Length != 2 || s.Chunk.Inner.Value != 3 {
+		t.Error("Error parsing big-endian chunk:", s.Chunk)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestParseErrorLocation(t *testing.T) {
+	type Name struct {
+		Length uint8
+		Text   string `len:"Length"`
+	}
+	type Entry struct {
+		ID   uint8
+		Name Name
+	}
+	s := struct {
+		Count   uint8
+		Entries []Entry `len:"Count"`
+	}{}
+	data := []byte{2, 1, 1, 'a', 2, 5, 'b'}
+	p := newParserData(data)
+
+	err := p.EmitReadStruct(&s)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatal("Expected a ParseError, got", err)
+	}
+	if perr.FieldPath != "Entries[1].Name.Text" {
+		t.Error("Invalid field path:", perr.FieldPath)
+	}
+	if perr.Offset != 6 {
+		t.Error("Invalid error offset:", perr.Offset)
+	}
+	if perr.Type != reflect.TypeOf("") {
+		t.Error("Invalid error type:", perr.Type)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected the error to wrap io.ErrUnexpectedEOF:", perr.Err)
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	type Entry struct {
+		ID   uint8
+		Name string `len:"2"`
+	}
+	s := struct {
+		Count   uint8
+		Entries []Entry `len:"Count"`
+	}{}
+	data := []byte{3, 1, 'a', 'b', 2, 'c', 'd', 3, 'e'}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err == nil {
+		t.Error("Expected an error")
+	}
+
+	if s.Count != 3 || len(s.Entries) != 3 || s.Entries[1].Name != "cd" || s.Entries[2].ID != 3 {
+		t.Error("Expected fields before the failure to be kept:", s)
+	}
+	path, offset := p.Progress()
+	if path != "Entries[2].ID" || offset != 8 {
+		t.Error("Invalid progress:", path, offset)
+	}
+}
+
 func TestPanickyMode(t *testing.T) {
+	s := struct{ A uint32 }{}
+	p := NewParser(bytes.NewReader([]byte{1, 2}), LittleEndian, Panicky)
+
 	defer func() {
+		r := recover()
+		if perr, ok := r.(*ParseError); !ok || !errors.Is(perr, ErrUnexpectedEOF) {
+			t.Errorf("Expected a panic with ErrUnexpectedEOF. Got %v", r)
+		}
 	}()
+	p.EmitReadStruct(&s)
+	t.Error("Expected a panic")
+}
+
+func TestLogger(t *testing.T) {
+	s := struct {
+		Version uint8 `warn:"!=1"`
+	}{}
+	p := newParserData([]byte{1})
+	var buf bytes.Buffer
+	p.SetLogger(log.New(&buf, "[bingo]: ", 0))
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[bingo]: Warning: Unexpected value in 'Version uint8' at offset 0: expected != 1, got 1.\n" {
+		t.Errorf("Incorrect log: %q", buf.String())
+	}
+
+	buf.Reset()
+	p = newParserData([]byte{1})
+	p.SetLogger(nil)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log. Got %q", buf.String())
+	}
+}
+
+type allocElem struct {
+	A uint16
+	B uint8  `assert:"<100"`
+	C []byte `len:"1"`
+}
+
+func TestSliceAllocations(t *testing.T) {
+	allocs := func(n int) float64 {
+		data := make([]byte, 1+4*n)
+		data[0] = byte(n)
+		var s struct {
+			N     uint8
+			Elems []allocElem `len:"N"`
+		}
+		return testing.AllocsPerRun(10, func() {
+			p := newParserData(data)
+			if err := p.EmitReadStruct(&s); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	// Only the slice inside each element should be allocated
+	if perElem := (allocs(200) - allocs(100)) / 100; perElem > 1 {
+		t.Errorf("Too many allocations per element: %v", perElem)
+	}
+}
+
+func TestReuseSlices(t *testing.T) {
+	type entry struct {
+		Kind  uint8
+		Value uint8  `if:"Kind == 1"`
+		Tag   string `len:"1"`
+	}
+	s := struct {
+		Count   uint8
+		Entries []entry  `len:"Count"`
+		Samples []uint16 `len:"Count"`
+	}{}
+	p := NewParser(bytes.NewReader([]byte{2, 1, 5, 'a', 1, 6, 'b', 1, 0, 2, 0, 1, 0, 'c', 3, 0}), LittleEndian, ReuseSlices)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	entries, samples := s.Entries, s.Samples
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if &s.Entries[0] != &entries[0] || &s.Samples[0] != &samples[0] {
+		t.Error("Expected the slices to be reused")
+	}
+	// The entry has no value this time, which mustn't be left over from before
+	if len(s.Entries) != 1 || s.Entries[0] != (entry{0, 0, "c"}) || len(s.Samples) != 1 || s.Samples[0] != 3 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+}
+
+func TestSlicesNotReused(t *testing.T) {
+	s := struct {
+		Count   uint8
+		Samples []uint16 `len:"Count"`
+	}{}
+	p := newParserData([]byte{1, 1, 0, 1, 2, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	samples := s.Samples
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if samples[0] != 1 || s.Samples[0] != 2 {
+		t.Error("Expected a new slice:", samples, s.Samples)
+	}
+}
+
+type fixedRecord struct {
+	Magic [2]byte `magic:"'BG'"`
+	Kind  uint8   `assert:"<4"`
+	Flags uint8   `pad:"2"`
+	Len   uint16
+	Seq   uint32
+	Addr  [4]byte
+	Inner struct {
+		A, B uint16
+	}
+}
+
+var fixedRecordData = []byte{'B', 'G', 1, 0, 0, 8, 0, 1, 0, 0, 0, 127, 0, 0, 1, 1, 0, 2, 0}
+
+func TestFixedSizeNoAllocations(t *testing.T) {
+	for _, opts := range []ParseOptions{Default, Unsafe, Strict} {
+		r := bytes.NewReader(fixedRecordData)
+		p := NewParser(r, LittleEndian, opts)
+		var s fixedRecord
+		allocs := testing.AllocsPerRun(100, func() {
+			r.Reset(fixedRecordData)
+			if err := p.EmitReadStruct(&s); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("Expected no allocations with options %v. Got %v", opts, allocs)
+		}
+		if s.Len != 8 || s.Addr != [4]byte{127, 0, 0, 1} || s.Inner.B != 2 {
+			t.Errorf("Incorrect struct: %+v", s)
+		}
+	}
+}
+
+func TestSizedStructsNoAllocations(t *testing.T) {
+	type record struct {
+		Size  uint8
+		Inner struct {
+			Size  uint8
+			Inner struct{ A, B uint8 } `size:"Size"`
+		} `size:"Size"`
+	}
+	var s struct {
+		First  record
+		Second record
+	}
+	data := []byte{3, 2, 1, 2, 3, 2, 3, 4}
+	r := bytes.NewReader(data)
+	p := NewParser(r, LittleEndian, Default)
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(data)
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("Expected no allocations. Got", allocs)
+	}
+	if s.Second.Inner.Inner.B != 4 || len(p.limiters) != 2 {
+		t.Errorf("Incorrect struct: %+v with %v limiters", s, len(p.limiters))
+	}
+}
+
+func BenchmarkFixedSizeStruct(b *testing.B) {
+	r := bytes.NewReader(fixedRecordData)
+	p := NewParser(r, LittleEndian, Default)
+	var s fixedRecord
+	b.ReportAllocs()
+	b.SetBytes(int64(len(fixedRecordData)))
+	for b.Loop() {
+		r.Reset(fixedRecordData)
+		if err := p.EmitReadStruct(&s); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
 
 /* Next up */
@@ -1224,3 +1935,127 @@ func isEqualu16(a []uint16, b []uint16) bool {
 	}
 	return true
 }
+
+func TestReadValue(t *testing.T) {
+	data := append(bytes.Clone(blobData), blobData...)
+	p := newParserData(data)
+
+	// A struct made at run time, parsed through a pointer
+	ptr := reflect.New(reflect.TypeOf(blob{}))
+	if err := p.ReadValue(ptr); err != nil {
+		t.Fatal(err)
+	}
+	// An addressable struct
+	items := reflect.MakeSlice(reflect.TypeOf([]blob{}), 1, 1)
+	if err := p.ReadValue(items.Index(0)); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []blob{*ptr.Interface().(*blob), items.Index(0).Interface().(blob)} {
+		if string(b.Name) != "abc" || b.Sum != 0x0201 {
+			t.Error("Incorrect blob:", b)
+		}
+	}
+
+	for _, v := range []reflect.Value{
+		{},
+		reflect.ValueOf(blob{}),
+		reflect.ValueOf((*blob)(nil)),
+		reflect.ValueOf(new(int)),
+		reflect.ValueOf(&struct{ b blob }{}).Elem().Field(0),
+	} {
+		if err := p.ReadValue(v); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Expected an unsupported type error for %v. Got %v", v, err)
+		}
+	}
+}
+
+type contextEntry struct {
+	ID   uint8  `after:"Check"`
+	Name []byte `len:"1"`
+}
+
+func (e *contextEntry) Check(p *Parser) error {
+	file, err := ContextAs[*contextFile](p)
+	if err != nil {
+		return err
+	}
+	if e.ID >= file.Count {
+		return fmt.Errorf("entry %v out of %v", e.ID, file.Count)
+	}
+	return nil
+}
+
+type contextFile struct {
+	Count   uint8
+	Entries []contextEntry `len:"Count"`
+}
+
+func TestContextAs(t *testing.T) {
+	var f contextFile
+	p := newParserData([]byte{2, 0, 'a', 1, 'b'})
+	if _, err := ContextAs[*contextFile](p); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an error without a context. Got", err)
+	}
+	if err := p.EmitReadStruct(&f); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Entries) != 2 || f.Entries[1].ID != 1 {
+		t.Errorf("Incorrect file: %+v", f)
+	}
+
+	// The entry is parsed on its own, so the context is of another type
+	var e contextEntry
+	p = newParserData([]byte{0, 'a'})
+	if err := p.EmitReadStruct(&e); !errors.Is(err, ErrUnsupportedType) || !strings.Contains(err.Error(), "*bingo.contextEntry") {
+		t.Error("Expected an error for the wrong context. Got", err)
+	}
+}
+
+type ancestorLeaf struct {
+	Value uint8  `after:"Check"`
+	Name  []byte `len:"1"`
+}
+
+func (l *ancestorLeaf) Check(p *Parser) error {
+	mid, ok := p.Parent().(*ancestorMiddle)
+	if !ok {
+		return fmt.Errorf("unexpected parent %T", p.Parent())
+	}
+	ancestors := p.Ancestors()
+	if len(ancestors) != 2 || ancestors[0] != mid || ancestors[1] != p.Context() {
+		return fmt.Errorf("unexpected ancestors %v", ancestors)
+	}
+	if top := ancestors[1].(*ancestorTop); l.Value > top.Max || l.Value < mid.Min {
+		return fmt.Errorf("value %v out of range", l.Value)
+	}
+	return nil
+}
+
+type ancestorMiddle struct {
+	Min    uint8
+	Leaves []ancestorLeaf `len:"2"`
+}
+
+type ancestorTop struct {
+	Max    uint8
+	Middle ancestorMiddle
+}
+
+func TestParentAncestors(t *testing.T) {
+	var top ancestorTop
+	p := newParserData([]byte{5, 1, 1, 'a', 5, 'b'})
+	if p.Parent() != nil || p.Ancestors() != nil {
+		t.Error("Expected no ancestors before parsing")
+	}
+	if err := p.EmitReadStruct(&top); err != nil {
+		t.Fatal(err)
+	}
+	if top.Middle.Leaves[1].Value != 5 {
+		t.Errorf("Incorrect struct: %+v", top)
+	}
+
+	p = newParserData([]byte{5, 1, 1, 'a', 6, 'b'})
+	if err := p.EmitReadStruct(&top); !errors.Is(err, ErrVerifyFailed) {
+		t.Error("Expected a verification error. Got", err)
+	}
+}
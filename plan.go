@@ -0,0 +1,197 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// What the parser needs to know about a struct type, worked out from the
+// type once rather than for every struct it parses
+type structPlan struct {
+	fields   []fieldPlan
+	captures map[string][]capture
+
+	// methods of the pointer to the struct, by name
+	methods map[string]reflect.Method
+
+	// whether the struct can be read straight into memory with the Unsafe
+	// option
+	raw bool
+}
+
+type fieldPlan struct {
+	reflect.StructField
+
+	// values of the known tags set on the field, by name
+	tags map[string]string
+
+	// whether the field is left alone, being excluded with `bingo:"-"` or
+	// being filled in from another field
+	skip bool
+
+	// what's wrong with the field's tags, for Strict mode
+	tagProblem string
+
+	// size of the field if it's plain fixed-size data, and the number of
+	// such fields in a row starting with it and their total size
+	size, run, runSize int
+
+	// number of fields of the run, starting with the field, that lie in
+	// memory in one piece, laid out as in the stream
+	packed int
+}
+
+func newStructPlan(typ reflect.Type) *structPlan {
+	plan := &structPlan{
+		fields:   make([]fieldPlan, typ.NumField()),
+		captures: structCaptures(typ),
+		methods:  make(map[string]reflect.Method),
+	}
+	for i := range plan.fields {
+		fieldtyp := typ.Field(i)
+		f := fieldPlan{StructField: fieldtyp, skip: isIgnored(fieldtyp) || isCapture(fieldtyp), tagProblem: tagProblem(fieldtyp)}
+		for tag := range knownTags {
+			if value := getTag(fieldtyp, tag); len(value) > 0 {
+				if f.tags == nil {
+					f.tags = make(map[string]string)
+				}
+				f.tags[tag] = value
+			}
+		}
+		plan.fields[i] = f
+	}
+	ptrtyp := reflect.PtrTo(typ)
+	for i := 0; i < ptrtyp.NumMethod(); i++ {
+		meth := ptrtyp.Method(i)
+		plan.methods[meth.Name] = meth
+	}
+	plan.findFixedRuns()
+	plan.raw = isRawStruct(typ, plan)
+	return plan
+}
+
+var planCache sync.Map // map[reflect.Type]*structPlan
+
+// Returns the plan for parsing a struct of type typ, working it out the
+// first time the type is parsed.
+func planFor(typ reflect.Type) *structPlan {
+	if plan, ok := planCache.Load(typ); ok {
+		return plan.(*structPlan)
+	}
+	plan, _ := planCache.LoadOrStore(typ, newStructPlan(typ))
+	return plan.(*structPlan)
+}
+
+// Looks up a method of the struct ptrtyp points to.
+func methodByName(ptrtyp reflect.Type, name string) (reflect.Method, bool) {
+	if ptrtyp.Kind() != reflect.Ptr || ptrtyp.Elem().Kind() != reflect.Struct {
+		return ptrtyp.MethodByName(name)
+	}
+	meth, ok := planFor(ptrtyp.Elem()).methods[name]
+	return meth, ok
+}
+
+// A Decoder parses structs of a single type, with the type's tags analyzed
+// and checked up front by Compile.
+type Decoder struct {
+	typ reflect.Type
+}
+
+// Compile analyzes the struct type typ, along with the struct types nested
+// in it, and returns a Decoder for it. It checks the tags the way the Strict
+// option does, reports length tags that conflict with each other, malformed
+// `pad`, `align` and `if` values, references to fields that don't exist or
+// aren't integers, and methods named by tags that don't exist or have the
+// wrong signature, so that mistakes in the struct definition surface before
+// any data is parsed.
+//
+// The analysis is shared with EmitReadStruct, which does it anyway the first
+// time it comes across a type, so Compile mostly serves to catch errors
+// early.
+//
+// The types of interface fields are looked up while parsing and aren't
+// covered.
+func Compile(typ reflect.Type) (*Decoder, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, &ParseError{kind: ErrUnsupportedType, text: fmt.Sprintf("Invalid argument type %v. Expected a struct type.", typ)}
+	}
+	if err := compile(typ, make(map[reflect.Type]bool)); err != nil {
+		return nil, err
+	}
+	return &Decoder{typ: typ}, nil
+}
+
+// Checks the plan for typ and those for the struct types nested in it,
+// skipping the types in seen.
+func compile(typ reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+	plan := planFor(typ)
+
+	for _, f := range plan.fields {
+		if len(f.tagProblem) > 0 {
+			return &ParseError{FieldPath: f.Name, Type: f.Type, kind: ErrInvalidTag, text: f.tagProblem}
+		}
+		for _, tag := range methodTags {
+			name, _, _ := strings.Cut(f.tags[tag], ",")
+			name = strings.TrimSuffix(name, "()")
+			if len(name) == 0 || tag == "demux" && name == "-" {
+				continue
+			}
+			if _, ok := plan.methods[name]; !ok {
+				article := "a"
+				if strings.ContainsRune("aeiou", rune(tag[0])) {
+					article = "an"
+				}
+				return &ParseError{FieldPath: f.Name, Type: f.Type, kind: ErrTagReference, text: fmt.Sprintf("Method '%v()' for '*%v' not found. Referenced from %v `%v` tag.", name, typ, article, tag)}
+			}
+		}
+		if meth, ok := plan.methods[f.tags["after"]]; ok && !isVerifySignature(meth.Type) {
+			return &ParseError{FieldPath: f.Name, Type: f.Type, kind: ErrTagReference, text: fmt.Sprintf("Proper '%v' method not found on the type *%v.", meth.Name, typ)}
+		}
+		if err := checkFieldTags(typ, plan, &f); err != nil {
+			return err
+		}
+		if err := compileNested(f.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compiles the struct types whose fields get parsed as part of a field of
+// type typ.
+func compileNested(typ reflect.Type, seen map[reflect.Type]bool) error {
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return compileNested(typ.Elem(), seen)
+	case reflect.Map:
+		if err := compileNested(typ.Key(), seen); err != nil {
+			return err
+		}
+		return compileNested(typ.Elem(), seen)
+	case reflect.Struct:
+		if typ != timeType {
+			return compile(typ, seen)
+		}
+	}
+	return nil
+}
+
+// Type returns the struct type the Decoder parses.
+func (d *Decoder) Type() reflect.Type {
+	return d.typ
+}
+
+// Decode parses the stream of p into the struct data points to, which must
+// be of the Decoder's type, as EmitReadStruct does.
+func (d *Decoder) Decode(p *Parser, data interface{}) error {
+	if typ := reflect.TypeOf(data); typ == nil || typ.Kind() != reflect.Ptr || typ.Elem() != d.typ {
+		return p.errorf(ErrUnsupportedType, nil, "Invalid argument type %v. Expected *%v.", typ, d.typ)
+	}
+	return p.EmitReadStruct(data)
+}
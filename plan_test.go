@@ -0,0 +1,110 @@
+package bingo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type CompiledRecord struct {
+	Kind    uint8
+	Count   uint8
+	Entries []traceEntry `len:"Count"`
+	Tail    uint16       `after:"CheckTail"`
+}
+
+func (r *CompiledRecord) CheckTail(p *Parser) error {
+	if r.Tail != 0xFFFF {
+		return errors.New("bad tail")
+	}
+	return nil
+}
+
+func TestCompile(t *testing.T) {
+	d, err := Compile(reflect.TypeOf(CompiledRecord{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Type() != reflect.TypeOf(CompiledRecord{}) {
+		t.Errorf("Incorrect type: %v", d.Type())
+	}
+
+	data := []byte{7, 1, 2, 0, 'a', 'b', 'c', 'd', 0xFF, 0xFF, 9, 0, 0xFF, 0xFF}
+	p := newParserData(data)
+	var r CompiledRecord
+	if err := d.Decode(p, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Kind != 7 || len(r.Entries) != 1 || r.Entries[0].ID != 2 || string(r.Entries[0].Name[:]) != "abcd" {
+		t.Errorf("Incorrect record: %+v", r)
+	}
+
+	// The same decoder is reused for the next record
+	r = CompiledRecord{}
+	if err := d.Decode(p, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Kind != 9 || len(r.Entries) != 0 || p.Offset() != uint64(len(data)) {
+		t.Errorf("Incorrect record: %+v", r)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		typ     reflect.Type
+		kind    error
+		message string
+	}{
+		{reflect.TypeOf(0), ErrUnsupportedType, "Invalid argument type int. Expected a struct type."},
+		{reflect.TypeOf(struct {
+			Data []byte `lenght:"4"`
+		}{}), ErrInvalidTag, "Unknown tag `lenght` on 'Data []uint8'. Did you mean `len`?"},
+		{reflect.TypeOf(struct {
+			Inner []struct {
+				A uint8 `after:"Missing"`
+			} `len:"1"`
+		}{}), ErrTagReference, "Method 'Missing()' for '*struct { A uint8 \"after:\\\"Missing\\\"\" }' not found. Referenced from an `after` tag."},
+		{reflect.TypeOf(struct {
+			A uint8 `parser:"Missing"`
+		}{}), ErrTagReference, "Method 'Missing()' for '*struct { A uint8 \"parser:\\\"Missing\\\"\" }' not found. Referenced from a `parser` tag."},
+		{reflect.TypeOf(struct {
+			Blobs []blob `until:"Missing"`
+		}{}), ErrTagReference, "Method 'Missing()' for '*struct { Blobs []bingo.blob \"until:\\\"Missing\\\"\" }' not found. Referenced from an `until` tag."},
+		{reflect.TypeOf(struct {
+			Count    uint8
+			_        struct{} `len:"Count" demux:"Missing(),uint16"`
+			Comments []blob   `demux:"-"`
+		}{}), ErrTagReference, "Method 'Missing()' for '*struct { Count uint8; _ struct {} \"len:\\\"Count\\\" demux:\\\"Missing(),uint16\\\"\"; Comments []bingo.blob \"demux:\\\"-\\\"\" }' not found. Referenced from a `demux` tag."},
+	}
+	for _, test := range tests {
+		_, err := Compile(test.typ)
+		if !errors.Is(err, test.kind) || err.Error() != test.message {
+			t.Error("Incorrect error:", err)
+		}
+	}
+}
+
+func TestDecoderWrongType(t *testing.T) {
+	d, err := Compile(reflect.TypeOf(traceEntry{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r CompiledRecord
+	if err := d.Decode(newParserData([]byte{1, 2}), &r); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+func TestPlanCache(t *testing.T) {
+	typ := reflect.TypeOf(CompiledRecord{})
+	plan := planFor(typ)
+	if planFor(typ) != plan {
+		t.Error("Expected the plan to be reused")
+	}
+	if len(plan.fields) != 4 || plan.fields[2].tags["len"] != "Count" || plan.fields[3].tags["after"] != "CheckTail" {
+		t.Errorf("Incorrect plan: %+v", plan.fields)
+	}
+	if _, ok := plan.methods["CheckTail"]; !ok {
+		t.Error("Expected CheckTail among the methods")
+	}
+}
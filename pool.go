@@ -0,0 +1,71 @@
+package bingo
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Pooled buffers come in sizes that are powers of two, from 64 bytes up to
+// readChunk. Larger ones are left to the garbage collector.
+const minPooledBits = 6
+
+var bufPools [21 - minPooledBits]sync.Pool
+
+// Returns the pool for buffers that hold n bytes, or nil if they're too large
+// to be pooled.
+func bufPool(n int) (*sync.Pool, int) {
+	class := 0
+	if n > 1<<minPooledBits {
+		class = bits.Len(uint(n-1)) - minPooledBits
+	}
+	if class >= len(bufPools) {
+		return nil, 0
+	}
+	return &bufPools[class], 1 << (class + minPooledBits)
+}
+
+// Holders for the buffers in bufPools, kept apart so that putting a buffer
+// back doesn't allocate one
+var bufHolders sync.Pool
+
+// Returns a buffer of n bytes from the pool, to be given back with putBuf.
+func getBuf(n int) []byte {
+	pool, size := bufPool(n)
+	if pool == nil {
+		return make([]byte, n)
+	}
+	if holder, ok := pool.Get().(*[]byte); ok {
+		buf := *holder
+		*holder = nil
+		bufHolders.Put(holder)
+		return buf[:n]
+	}
+	return make([]byte, n, size)
+}
+
+// Gives a buffer from getBuf back to the pool. It must no longer be used.
+func putBuf(buf []byte) {
+	if pool, size := bufPool(cap(buf)); pool != nil && size == cap(buf) {
+		holder, ok := bufHolders.Get().(*[]byte)
+		if !ok {
+			holder = new([]byte)
+		}
+		*holder = buf
+		pool.Put(holder)
+	}
+}
+
+// Reads the next nbytes bytes of the stream into a buffer from the pool,
+// for bytes that are only looked at before moving on. The buffer is to be
+// given back with putBuf.
+func (p *Parser) readTemp(nbytes int) ([]byte, error) {
+	if pool, _ := bufPool(nbytes); pool == nil {
+		return p.EmitReadNBytes(nbytes)
+	}
+	buf := getBuf(nbytes)
+	if err := p.EmitReadFull(buf); err != nil {
+		putBuf(buf)
+		return nil, err
+	}
+	return buf, nil
+}
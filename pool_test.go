@@ -0,0 +1,50 @@
+package bingo
+
+import (
+	"testing"
+)
+
+func TestBufPool(t *testing.T) {
+	tests := []struct {
+		n, size int
+	}{
+		{0, 64}, {1, 64}, {64, 64}, {65, 128}, {1000, 1024}, {readChunk, readChunk}, {readChunk + 1, 0},
+	}
+	for _, test := range tests {
+		if pool, size := bufPool(test.n); size != test.size || (pool == nil) != (test.size == 0) {
+			t.Errorf("Incorrect size class for %v: %v", test.n, size)
+		}
+		buf := getBuf(test.n)
+		if len(buf) != test.n || test.size > 0 && cap(buf) != test.size {
+			t.Errorf("Incorrect buffer for %v: len %v, cap %v", test.n, len(buf), cap(buf))
+		}
+		putBuf(buf)
+	}
+}
+
+func TestPooledRegions(t *testing.T) {
+	type record struct {
+		ID   uint16
+		Name string `len:"2"`
+	}
+	data := []byte{8, 1, 0, 'a', 'b', 2, 0, 'c', 'd', 2, 0, 3, 0}
+	for i := 0; i < 3; i++ {
+		s := struct {
+			Size    uint8
+			Records []record `size:"Size"`
+			Pad     uint8    `pad:"2"`
+			Tail    uint16
+		}{}
+		p := newParserData(data)
+
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Records) != 2 || s.Records[0] != (record{1, "ab"}) || s.Records[1] != (record{2, "cd"}) || s.Pad != 2 || s.Tail != 3 {
+			t.Errorf("Incorrect struct: %+v", s)
+		}
+		if len(p.Warnings()) != 0 {
+			t.Error("Unexpected warnings:", p.Warnings())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package bingo
+
+// Helpers for code that reads parts of the stream by hand, such as methods
+// named by `parser` tags or loops over irregular regions between calls to
+// EmitReadStruct:
+//
+//	count, err := p.ReadUint16()
+//	...
+//	name, err := p.ReadString(int(count))
+//
+// They read in the parser's current byte order and advance its offset like
+// fields do. A pending partial byte of bit fields is dropped first.
+
+// ReadUint8 reads the next byte of the stream.
+func (p *Parser) ReadUint8() (uint8, error) {
+	buf, err := p.readPrimitive(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// ReadUint16 reads a 16-bit integer in the parser's byte order.
+func (p *Parser) ReadUint16() (uint16, error) {
+	buf, err := p.readPrimitive(2)
+	if err != nil {
+		return 0, err
+	}
+	return p.byteOrder.Uint16(buf), nil
+}
+
+// ReadUint32 reads a 32-bit integer in the parser's byte order.
+func (p *Parser) ReadUint32() (uint32, error) {
+	buf, err := p.readPrimitive(4)
+	if err != nil {
+		return 0, err
+	}
+	return p.byteOrder.Uint32(buf), nil
+}
+
+// ReadUint64 reads a 64-bit integer in the parser's byte order.
+func (p *Parser) ReadUint64() (uint64, error) {
+	buf, err := p.readPrimitive(8)
+	if err != nil {
+		return 0, err
+	}
+	return p.byteOrder.Uint64(buf), nil
+}
+
+// ReadBytes reads the next n bytes of the stream. Like []byte fields, the
+// bytes of parsers made with the AliasInput option may point into the input.
+func (p *Parser) ReadBytes(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, p.errorf(ErrInvalidLength, nil, "Unable to read %v bytes at offset %v.", n, p.offset)
+	}
+	p.alignBits()
+	return p.readFieldBytes(n)
+}
+
+// ReadString reads the next n bytes of the stream as a string.
+func (p *Parser) ReadString(n int) (string, error) {
+	buf, err := p.ReadBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// Reads n bytes into the scratch buffer, which is only valid until the next
+// read.
+func (p *Parser) readPrimitive(n int) ([]byte, error) {
+	p.alignBits()
+	buf := p.scratchBuf(n)
+	if err := p.EmitReadFull(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
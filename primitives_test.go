@@ -0,0 +1,60 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadPrimitives(t *testing.T) {
+	data := []byte{
+		1,
+		2, 0,
+		3, 0, 0, 0,
+		4, 0, 0, 0, 0, 0, 0, 0,
+		'a', 'b', 'c',
+		0, 5, // big-endian
+		1, 'x', 1, 'y', 0, 9, // a blob
+		'z',
+	}
+	p := newParserData(data)
+
+	u8, err := p.ReadUint8()
+	if err != nil || u8 != 1 {
+		t.Fatal("Incorrect uint8:", u8, err)
+	}
+	if u16, err := p.ReadUint16(); err != nil || u16 != 2 {
+		t.Fatal("Incorrect uint16:", u16, err)
+	}
+	if u32, err := p.ReadUint32(); err != nil || u32 != 3 {
+		t.Fatal("Incorrect uint32:", u32, err)
+	}
+	if u64, err := p.ReadUint64(); err != nil || u64 != 4 {
+		t.Fatal("Incorrect uint64:", u64, err)
+	}
+	if s, err := p.ReadString(3); err != nil || s != "abc" {
+		t.Fatal("Incorrect string:", s, err)
+	}
+	p.SetByteOrder(BigEndian)
+	if u16, err := p.ReadUint16(); err != nil || u16 != 5 {
+		t.Fatal("Incorrect big-endian uint16:", u16, err)
+	}
+
+	// Interleaved with structs
+	var b blob
+	if err := p.EmitReadStruct(&b); err != nil || string(b.Name) != "x" || string(b.Data) != "y" || b.Sum != 9 {
+		t.Fatal("Incorrect blob:", b, err)
+	}
+	if buf, err := p.ReadBytes(1); err != nil || string(buf) != "z" {
+		t.Fatal("Incorrect bytes:", buf, err)
+	}
+	if p.Offset() != uint64(len(data)) {
+		t.Error("Incorrect offset:", p.Offset())
+	}
+
+	if _, err := p.ReadUint32(); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Expected an unexpected EOF error. Got", err)
+	}
+	if _, err := p.ReadBytes(-1); !errors.Is(err, ErrInvalidLength) {
+		t.Error("Expected an invalid length error. Got", err)
+	}
+}
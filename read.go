@@ -0,0 +1,104 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+)
+
+// Read parses a T from r and returns it, for callers that would otherwise
+// declare a variable only to pass a pointer to it to EmitReadStruct:
+//
+//	header, err := bingo.Read[Header](f, bingo.LittleEndian)
+//
+// T must be a struct type. Any options given are combined, as if they had
+// been or'ed together. r isn't read past the end of the struct, so that
+// whatever follows can be read from it next; wrap it in a bufio.Reader if
+// it's slow to read from in small pieces.
+func Read[T any](r io.Reader, byteOrder ByteOrder, options ...ParseOptions) (*T, error) {
+	p := NewParser(nil, byteOrder, combineOptions(options))
+	p.setReader(r, 0)
+	v := new(T)
+	if err := p.EmitReadStruct(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ReadSlice parses n Ts from r, one after the other, for data made of a
+// known number of records with nothing around them. It reads from r the way
+// Read does. If one of the records fails to parse, those before it are
+// returned along with the error.
+func ReadSlice[T any](r io.Reader, byteOrder ByteOrder, n int, options ...ParseOptions) ([]T, error) {
+	p := NewParser(nil, byteOrder, combineOptions(options))
+	p.setReader(r, 0)
+	p.records = true
+	items := make([]T, n)
+	for i := range items {
+		if err := p.EmitReadStruct(&items[i]); err != nil {
+			return items[:i], err
+		}
+	}
+	return items, nil
+}
+
+// ReadAll parses Ts from r, one after the other, until r runs out, for files
+// made of nothing but records back to back. The data ending partway through
+// a record is an error, in which case the records before it are returned
+// along with the error.
+func ReadAll[T any](r io.Reader, byteOrder ByteOrder, options ...ParseOptions) ([]T, error) {
+	p := NewParser(r, byteOrder, combineOptions(options))
+	var items []T
+	for {
+		var item T
+		if err := p.Next(&item); err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+// Next parses the next record of a stream made of records back to back into
+// the struct dst points to, as EmitReadStruct does. It returns io.EOF itself
+// when the stream ends right where the record would start, so that the
+// records can be read in a loop:
+//
+//	for {
+//		var packet Packet
+//		if err := p.Next(&packet); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The stream ending partway through a record is an error like any other.
+// With the Panicky option, io.EOF is still returned rather than raised.
+func (p *Parser) Next(dst interface{}) error {
+	start, panicky, records := p.offset, p.panicky, p.records
+	p.panicky, p.records = false, true
+	err := p.EmitReadStruct(dst)
+	p.panicky, p.records = panicky, records
+
+	if err != nil && errors.Is(err, io.EOF) && p.offset == start {
+		return io.EOF
+	}
+	if err == nil && p.offset == start {
+		// Reading on would never get anywhere
+		err = p.errorf(ErrUnsupportedType, nil, "Unable to read records of type %T one after the other. They take up no bytes.", dst)
+	}
+	if err != nil && p.panicky {
+		panic(err)
+	}
+	return err
+}
+
+func combineOptions(options []ParseOptions) ParseOptions {
+	var combined ParseOptions
+	for _, opt := range options {
+		combined |= opt
+	}
+	return combined
+}
@@ -0,0 +1,102 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	r := bytes.NewBuffer(append(bytes.Clone(blobData), 0xEE))
+	s, err := Read[blob](r, LittleEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.Name) != "abc" || s.Sum != 0x201 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+	if r.Len() != 1 {
+		t.Error("Read past the end of the struct:", r.Len())
+	}
+
+	if _, err := Read[blob](bytes.NewBuffer(blobData[:3]), LittleEndian, Strict, CollectErrors); !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Expected an unexpected EOF error. Got", err)
+	}
+	if _, err := Read[int](bytes.NewBuffer(blobData), LittleEndian); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an unsupported type error. Got", err)
+	}
+}
+
+func TestReadSlice(t *testing.T) {
+	data := append(bytes.Clone(blobData), blobData...)
+	r := bytes.NewBuffer(append(data, 0xEE))
+	items, err := ReadSlice[blob](r, LittleEndian, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || string(items[1].Name) != "abc" || items[1].Sum != 0x201 {
+		t.Errorf("Incorrect items: %+v", items)
+	}
+	if r.Len() != 1 {
+		t.Error("Read past the end of the items:", r.Len())
+	}
+
+	items, err = ReadSlice[blob](bytes.NewBuffer(data), LittleEndian, 3)
+	if !errors.Is(err, ErrUnexpectedEOF) || len(items) != 2 {
+		t.Error("Expected an unexpected EOF error after two items. Got", err, len(items))
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	data := append(bytes.Clone(blobData), blobData...)
+	items, err := ReadAll[blob](bytes.NewBuffer(data), LittleEndian, Panicky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || string(items[1].Name) != "abc" || items[1].Sum != 0x201 {
+		t.Errorf("Incorrect items: %+v", items)
+	}
+
+	if items, err := ReadAll[blob](bytes.NewReader(nil), LittleEndian); err != nil || len(items) != 0 {
+		t.Error("Expected no items. Got", items, err)
+	}
+	items, err = ReadAll[blob](bytes.NewBuffer(data[:len(data)-1]), LittleEndian)
+	if !errors.Is(err, ErrUnexpectedEOF) || len(items) != 1 {
+		t.Error("Expected an unexpected EOF error after one item. Got", err, len(items))
+	}
+	if _, err := ReadAll[struct{}](bytes.NewBuffer(data), LittleEndian); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an unsupported type error. Got", err)
+	}
+}
+
+func TestNext(t *testing.T) {
+	data := append(bytes.Clone(blobData), blobData...)
+	p := NewParser(bytes.NewBuffer(data), LittleEndian, Panicky)
+	var names []string
+	for {
+		var b blob
+		if err := p.Next(&b); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, string(b.Name))
+	}
+	if len(names) != 2 || names[1] != "abc" {
+		t.Error("Incorrect records:", names)
+	}
+
+	// Ending partway through a record isn't the end of the stream
+	p = newParserData(data[:len(data)-1])
+	var b blob
+	if err := p.Next(&b); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Warnings()) > 0 {
+		t.Error("Unexpected warnings about the records to come:", p.Warnings())
+	}
+	if err := p.Next(&b); err == io.EOF || !errors.Is(err, ErrUnexpectedEOF) {
+		t.Error("Expected an unexpected EOF error. Got", err)
+	}
+}
@@ -0,0 +1,123 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// Ref is a field type for data stored elsewhere in the stream, such as the
+// targets of an offset table. While the containing struct is parsed, a Ref
+// consumes no bytes and only records the position given by its `offset` tag
+// (which accepts the same bases as on other fields) and, optionally, the
+// size given by its `size` tag:
+//
+//	type Section struct {
+//		Offset uint32
+//		Size   uint32
+//		Body   bingo.Ref[SectionBody] `offset:"Offset" size:"Size"`
+//	}
+//
+// The target is parsed when Resolve is called, which requires the parser's
+// reader to implement io.Seeker. T must be a struct type.
+type Ref[T any] struct {
+	p       *Parser
+	offset  uint64
+	size    uint64
+	hasSize bool
+}
+
+type refField interface {
+	setRef(p *Parser, offset, size uint64, hasSize bool)
+}
+
+func (r *Ref[T]) setRef(p *Parser, offset, size uint64, hasSize bool) {
+	r.p, r.offset, r.size, r.hasSize = p, offset, size, hasSize
+}
+
+// Offset returns the stream offset of the target.
+func (r *Ref[T]) Offset() uint64 {
+	return r.offset
+}
+
+// Size returns the size of the target and whether one was recorded.
+func (r *Ref[T]) Size() (uint64, bool) {
+	return r.size, r.hasSize
+}
+
+// Resolve parses the target into a new T. Each call parses it anew.
+func (r *Ref[T]) Resolve() (*T, error) {
+	if r.p == nil {
+		return nil, errors.New("bingo: Ref was not set by a parser")
+	}
+	v := new(T)
+	if err := r.p.readAt(r.offset, r.size, r.hasSize, v); err != nil {
+		if r.p.panicky {
+			panic(err)
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *Parser) readRef(ref refField, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	offsetkey := getTag(fieldtyp, "offset")
+	if len(offsetkey) == 0 {
+		return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Ref fields require an `offset` tag.", fieldtyp.Name, fieldtyp.Type)
+	}
+	offset, err := p.parseOffsetTag(offsetkey, fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+
+	var size uint64
+	sizekey := getTag(fieldtyp, "size")
+	if len(sizekey) > 0 {
+		if size, err = p.parseLengthTag("size", sizekey, fieldtyp, ptrval, -1); err != nil {
+			return err
+		}
+	}
+	ref.setRef(p, offset, size, len(sizekey) > 0)
+	return nil
+}
+
+// Parses the struct data points to at the given offset of the parser's
+// reader, then returns to where the parser was. If hasSize is set, exactly
+// size bytes must be consumed.
+func (p *Parser) readAt(offset, size uint64, hasSize bool, data interface{}) error {
+	seeker, ok := p.src.(io.Seeker)
+	if !ok {
+		return p.errorf(nil, nil, "Unable to read %v at offset %v. The reader doesn't implement io.Seeker.", reflect.TypeOf(data), offset)
+	}
+
+	tmp_r, tmp_offset, tmp_start, mark := p.r, p.offset, p.regionStart, p.nlimits
+	tmp_pos, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return p.wrapError(err)
+	}
+	defer func() {
+		seeker.Seek(tmp_pos, io.SeekStart)
+		p.r, p.offset, p.regionStart = tmp_r, tmp_offset, tmp_start
+		p.popLimits(mark)
+	}()
+
+	// Offsets are counted from where the parser's reader was at offset 0
+	if _, err := seeker.Seek(tmp_pos-int64(tmp_offset)+int64(offset), io.SeekStart); err != nil {
+		return p.wrapError(err)
+	}
+	p.r, p.offset, p.regionStart = p.src, offset, 0
+
+	if !hasSize {
+		return p.emitReadStruct(data)
+	}
+
+	limit_r := p.pushLimit(p.src, int64(size))
+	p.r, p.regionStart = limit_r, offset
+	if err := p.emitReadStruct(data); err != nil {
+		return err
+	}
+	if limit_r.N != 0 {
+		return p.errorf(ErrSizeMismatch, nil, "Error reading exactly %v bytes into %v at offset %v. Actual bytes read: %v", size, reflect.TypeOf(data), offset, int64(size)-limit_r.N)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+type SectionBody struct {
+	Length uint8
+	Text   []byte `len:"Length"`
+}
+
+type Section struct {
+	Offset uint8
+	Size   uint8
+	Body   Ref[SectionBody] `offset:"Offset" size:"Size"`
+}
+
+type SectionTable struct {
+	Count    uint8
+	Sections []Section `len:"Count"`
+	Trailer  uint8
+}
+
+var sectionData = []byte{2,
+	6, 3,
+	9, 2,
+	0xEE,
+	2, 'h', 'i',
+	1, 'x'}
+
+func TestRefField(t *testing.T) {
+	s := SectionTable{}
+	p := newParserData(sectionData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Trailer != 0xEE {
+		t.Error("Error parsing field after refs:", s.Trailer)
+	}
+	if p.offset != 6 {
+		t.Error("Invalid offset:", p.offset)
+	}
+	if s.Sections[1].Body.Offset() != 9 {
+		t.Error("Invalid ref offset:", s.Sections[1].Body.Offset())
+	}
+	if size, ok := s.Sections[1].Body.Size(); !(ok && size == 2) {
+		t.Error("Invalid ref size:", size, ok)
+	}
+
+	first, err := s.Sections[0].Body.Resolve()
+	if err != nil {
+		t.Error(err)
+	} else if string(first.Text) != "hi" {
+		t.Error("Error resolving first ref:", first)
+	}
+	second, err := s.Sections[1].Body.Resolve()
+	if err != nil {
+		t.Error(err)
+	} else if string(second.Text) != "x" {
+		t.Error("Error resolving second ref:", second)
+	}
+	if p.offset != 6 {
+		t.Error("Resolving refs moved the parser:", p.offset)
+	}
+}
+
+func TestRefFieldSizeMismatch(t *testing.T) {
+	data := append([]byte(nil), sectionData...)
+	data[2] = 4
+	s := SectionTable{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := s.Sections[0].Body.Resolve(); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading exactly 4 bytes into *bingo.SectionBody at offset 6. Actual bytes read: 3" {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestRefFieldNotSeekable(t *testing.T) {
+	s := SectionTable{}
+	p := NewParser(io.MultiReader(bytes.NewReader(sectionData)), LittleEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := s.Sections[0].Body.Resolve(); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Unable to read *bingo.SectionBody at offset 6. The reader doesn't implement io.Seeker." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
@@ -0,0 +1,94 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var registry = struct {
+	sync.RWMutex
+	types map[reflect.Type]map[uint64]reflect.Type
+}{types: make(map[reflect.Type]map[uint64]reflect.Type)}
+
+// RegisterType associates a discriminator value with a concrete type for
+// interface fields of the type iface points to. Interface fields are parsed
+// by reading the discriminator named by their `type` tag (a field or a
+// method, like `len`) and parsing into a new value of the registered type.
+//
+// iface must be a nil pointer to the interface type, e.g. (*Chunk)(nil), and
+// concrete must be a struct or a pointer to a struct implementing it:
+//
+//	bingo.RegisterType((*Chunk)(nil), 1, (*ImageChunk)(nil))
+//
+// RegisterType panics on invalid arguments or duplicate registrations. It is
+// meant to be called from init functions.
+func RegisterType(iface interface{}, value uint, concrete interface{}) {
+	ptrtyp := reflect.TypeOf(iface)
+	if ptrtyp == nil || ptrtyp.Kind() != reflect.Ptr || ptrtyp.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("bingo: RegisterType expects a pointer to an interface, got %v", ptrtyp))
+	}
+	ifacetyp := ptrtyp.Elem()
+
+	typ := reflect.TypeOf(concrete)
+	if typ == nil || !(typ.Kind() == reflect.Struct || typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct) {
+		panic(fmt.Sprintf("bingo: RegisterType expects a struct or a pointer to a struct, got %v", typ))
+	}
+	if !typ.Implements(ifacetyp) {
+		panic(fmt.Sprintf("bingo: %v does not implement %v", typ, ifacetyp))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	types := registry.types[ifacetyp]
+	if types == nil {
+		types = make(map[uint64]reflect.Type)
+		registry.types[ifacetyp] = types
+	}
+	if prev, ok := types[uint64(value)]; ok {
+		panic(fmt.Sprintf("bingo: value %v for %v already registered to %v", value, ifacetyp, prev))
+	}
+	types[uint64(value)] = typ
+}
+
+func lookupType(ifacetyp reflect.Type, value uint64) (reflect.Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	typ, ok := registry.types[ifacetyp][value]
+	return typ, ok
+}
+
+// Parses an interface field into a new value of the type registered for its
+// discriminator.
+func (p *Parser) readInterface(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	typekey := getTag(fieldtyp, "type")
+	if len(typekey) == 0 {
+		return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Interface fields require a `type` tag.", fieldtyp.Name, fieldtyp.Type)
+	}
+
+	value, err := p.parseRefTag("type", typekey, fieldtyp, ptrval, -1)
+	if err != nil {
+		return err
+	}
+	typ, ok := lookupType(fieldtyp.Type, value)
+	if !ok {
+		return p.errorf(nil, nil, "No type registered for value %v of '%v' in '%v %v'.", value, typekey, fieldtyp.Name, fieldtyp.Type)
+	}
+
+	structtyp := typ
+	if typ.Kind() == reflect.Ptr {
+		structtyp = typ.Elem()
+	}
+	elem := reflect.New(structtyp).Elem()
+	if err := p.readFieldOfLimitedSize("size", getTag(fieldtyp, "size"), elem, fieldtyp, ptrval, -1); err != nil {
+		return err
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		fieldval.Set(elem.Addr())
+	} else {
+		fieldval.Set(elem)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package bingo
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type Chunk interface {
+	ChunkName() string
+}
+
+type TextChunk struct {
+	Length uint8
+	Text   []byte `len:"Length"`
+}
+
+func (c *TextChunk) ChunkName() string {
+	return "text"
+}
+
+type PointChunk struct {
+	X, Y int16
+}
+
+func (c PointChunk) ChunkName() string {
+	return "point"
+}
+
+func init() {
+	RegisterType((*Chunk)(nil), 1, (*TextChunk)(nil))
+	RegisterType((*Chunk)(nil), 2, PointChunk{})
+}
+
+type TaggedChunk struct {
+	Kind uint8
+	Body Chunk `type:"Kind"`
+}
+
+func TestInterfaceField(t *testing.T) {
+	data := []byte{1, 3, 'a', 'b', 'c',
+		2, 1, 0, 2, 0}
+	s := struct {
+		First  TaggedChunk
+		Second TaggedChunk
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if text, ok := s.First.Body.(*TextChunk); !ok || string(text.Text) != "abc" {
+		t.Error("Error parsing pointer chunk:", s.First.Body)
+	}
+	if point, ok := s.Second.Body.(PointChunk); !ok || point.X != 1 || point.Y != 2 {
+		t.Error("Error parsing value chunk:", s.Second.Body)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestInterfaceFieldUnregistered(t *testing.T) {
+	data := []byte{3}
+	s := TaggedChunk{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "No type registered for value 3 of 'Kind' in 'Body bingo.Chunk'." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestRegisterTypeMismatch(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "bingo: bingo.TextChunk does not implement bingo.Chunk" {
+			t.Error("Incorrect panic:", r)
+		}
+	}()
+	RegisterType((*Chunk)(nil), 3, TextChunk{})
+}
+
+type SizedChunk struct {
+	Kind uint8
+	Size uint8
+	Body Chunk `type:"Kind" size:"Size"`
+	End  uint8
+}
+
+func TestInterfaceFieldSize(t *testing.T) {
+	data := []byte{2, 4, 1, 0, 2, 0, 0xEE}
+	var s SizedChunk
+	p := NewParser(bytes.NewReader(data), LittleEndian, Strict)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if point, ok := s.Body.(PointChunk); !ok || point.X != 1 || point.Y != 2 || s.End != 0xEE {
+		t.Error("Error parsing sized chunk:", s.Body, s.End)
+	}
+	if _, err := Compile(reflect.TypeOf(s)); err != nil {
+		t.Error(err)
+	}
+	if err := Validate(reflect.TypeOf(s)); err != nil {
+		t.Error(err)
+	}
+}
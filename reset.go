@@ -0,0 +1,39 @@
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"log"
+)
+
+// Logger for parsers that don't log, shared as it never writes anything
+var discardLogger = log.New(io.Discard, "", 0)
+
+// Reset makes the parser read from r as if it were new, so that parsers can
+// be kept in a sync.Pool and reused for each message instead of being made
+// anew. The offset, the context, the Tags, the values set with WithValue,
+// the byte order and the values interned with the Intern option go back to
+// how they were when the parser was created, while its options and settings,
+// such as the logger, OnError and the buffer size, are kept. Parsers for a
+// pool can be made with a nil reader.
+func (p *Parser) Reset(r io.Reader) {
+	p.setReader(r, p.bufferSize)
+	p.byteOrder = p.initialOrder
+	p.offset, p.regionStart, p.inputSize = 0, 0, 0
+	p.popLimits(0)
+	p.context, p.input = nil, nil
+	p.depth, p.stack, p.elemIndex = 0, p.stack[:0], -1
+	p.lastStack, p.lastOffset = p.lastStack[:0], 0
+	p.errors, p.warnings, p.fieldMap = nil, nil, nil
+	p.bits.cur, p.bits.nbits = 0, 0
+	clear(p.Tags)
+	clear(p.values)
+	clear(p.interned)
+	clear(p.internedBytes)
+}
+
+// ResetBytes is like Reset for a parser over data, as made by NewParserBytes.
+func (p *Parser) ResetBytes(data []byte) {
+	p.Reset(bytes.NewReader(data))
+	p.input, p.inputSize = data, uint64(len(data))
+}
@@ -0,0 +1,79 @@
+package bingo
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	var s struct {
+		Order [2]byte `byteorder:"'II','MM'"`
+		Value uint16
+	}
+	p := newParserData([]byte{'M', 'M', 1, 2})
+	p.Tags["version"] = 2
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Value != 0x102 || p.ByteOrder() != BigEndian {
+		t.Fatalf("Incorrect struct: %+v", s)
+	}
+
+	p.WithValue(versionKey{}, 2)
+	p.Reset(bytes.NewReader([]byte{'I', 'I', 1, 2, 3}))
+	if p.Offset() != 0 || p.Context() != nil || len(p.Tags) != 0 || p.Value(versionKey{}) != nil || p.ByteOrder() != LittleEndian {
+		t.Errorf("Parser not reset: offset %v, context %v, tags %v, order %v", p.Offset(), p.Context(), p.Tags, p.ByteOrder())
+	}
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Value != 0x201 || p.Offset() != 4 {
+		t.Errorf("Incorrect struct: %+v at offset %v", s, p.Offset())
+	}
+}
+
+func TestResetBytes(t *testing.T) {
+	var s blob
+	p := NewParserBytes([]byte{0}, LittleEndian, AliasInput)
+	data := bytes.Clone(blobData)
+	p.ResetBytes(data)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if &s.Name[0] != &data[1] {
+		t.Error("Expected the name to point into the new input")
+	}
+}
+
+func TestResetKeepsBufferSize(t *testing.T) {
+	p := NewParser(bytes.NewReader(nil), LittleEndian, Default)
+	p.SetBufferSize(0)
+	r := &readCounter{ReadSeeker: bytes.NewReader([]byte{1, 2, 3, 4, 5, 0, 6, 0, 0xFF})}
+	p.Reset(r)
+	var s smallFields
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 3 {
+		t.Error("Expected the reader not to be buffered. Reads:", r.reads)
+	}
+}
+
+func TestPooledParsers(t *testing.T) {
+	pool := sync.Pool{New: func() any { return NewParser(nil, LittleEndian, Default) }}
+	r := bytes.NewReader(nil)
+	var s fixedRecord
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(fixedRecordData)
+		p := pool.Get().(*Parser)
+		p.Reset(r)
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		pool.Put(p)
+	})
+	if allocs != 0 {
+		t.Error("Expected no allocations. Got", allocs)
+	}
+}
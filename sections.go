@@ -0,0 +1,120 @@
+package bingo
+
+import (
+	"io"
+	"maps"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// SectionSpec is a part of the stream for ParseSections to parse, such as
+// one listed in the offset table of a container's header.
+type SectionSpec struct {
+	// Stream offset and size in bytes of the section. A Size of 0 lets the
+	// section run up to the end of the input.
+	Offset uint64
+	Size   uint64
+	// Pointer to the struct to parse the section into
+	Dest interface{}
+}
+
+// ParseSections parses each of the given sections into its Dest, several at a
+// time, for formats whose header lists independent sections that would take
+// long to parse one after the other. The parser's reader has to implement
+// io.ReaderAt, as *os.File and *bytes.Reader do, and is read from at the
+// sections' positions without being moved.
+//
+// Each section is parsed by a parser of its own, with the byte order and the
+// options of this one, and must take up exactly its size if it has one. The
+// values set with WithValue are copied to them, while the parser's Tags,
+// context and hooks such as OnError aren't shared with them.
+// Sections that fail don't keep the others from being parsed; the errors
+// are returned together, as ParseErrors in the order of the sections.
+func (p *Parser) ParseSections(sections ...SectionSpec) error {
+	err := p.parseSections(sections)
+	clear(p.values)
+	if err != nil && p.panicky {
+		panic(err)
+	}
+	return err
+}
+
+func (p *Parser) parseSections(sections []SectionSpec) error {
+	r, ok := unbuffered(p.src).(io.ReaderAt)
+	if !ok {
+		return p.errorf(nil, nil, "Unable to parse sections at offset %v. Parsing sections requires a reader that implements io.ReaderAt.", p.offset)
+	}
+	// Position in r of offset 0, as the reader needn't have been at its
+	// start when the parse began
+	var base int64
+	if seeker, ok := p.src.(io.Seeker); ok {
+		pos, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return p.wrapError(err)
+		}
+		base = pos - int64(p.offset)
+	}
+
+	errs := make([]error, len(sections))
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i := range sections {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			errs[i] = p.parseSection(r, base, sections[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var merged ParseErrors
+	for _, err := range errs {
+		switch err := err.(type) {
+		case nil:
+		case ParseErrors:
+			merged = append(merged, err...)
+		case *ParseError:
+			merged = append(merged, err)
+		default:
+			merged = append(merged, p.parseError(nil, err, err.Error()))
+		}
+	}
+	if len(merged) > 0 {
+		return merged
+	}
+	return nil
+}
+
+// Parses a section found at position base+section.Offset of r with a parser
+// of its own.
+func (p *Parser) parseSection(r io.ReaderAt, base int64, section SectionSpec) error {
+	if section.Offset > math.MaxInt64-uint64(base) || section.Size > math.MaxInt64-uint64(base)-section.Offset {
+		return p.errorf(ErrInvalidLength, nil, "Unable to parse %v bytes at offset %v into %T. The section lies too far into the input.", section.Size, section.Offset, section.Dest)
+	}
+	pos := base + int64(section.Offset)
+	size := math.MaxInt64 - pos
+	if section.Size > 0 {
+		size = int64(section.Size)
+	}
+
+	sp := NewParser(io.NewSectionReader(r, pos, size), p.byteOrder, p.options&^Panicky)
+	sp.l = p.l
+	sp.values = maps.Clone(p.values)
+	// Offsets are those of the stream the section is part of
+	sp.offset = section.Offset
+	if section.Size > 0 {
+		sp.inputSize = section.Offset + section.Size
+	}
+	if err := sp.EmitReadStruct(section.Dest); err != nil {
+		return err
+	}
+	if section.Size > 0 && sp.offset != sp.inputSize {
+		return sp.errorf(ErrSizeMismatch, nil, "Error reading exactly %v bytes into %T at offset %v. Actual bytes read: %v", section.Size, section.Dest, section.Offset, sp.offset-section.Offset)
+	}
+	return nil
+}
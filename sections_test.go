@@ -0,0 +1,74 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type containerHeader struct {
+	Count   uint8
+	Entries []struct {
+		Offset uint8
+		Size   uint8
+	} `len:"Count"`
+}
+
+var containerData = []byte{3,
+	7, 3,
+	10, 2,
+	12, 0,
+	2, 'h', 'i',
+	1, 'x',
+	3, 'a', 'b', 'c'}
+
+func TestParseSections(t *testing.T) {
+	var header containerHeader
+	p := newParserData(containerData)
+	if err := p.EmitReadStruct(&header); err != nil {
+		t.Fatal(err)
+	}
+	bodies := make([]SectionBody, len(header.Entries))
+	sections := make([]SectionSpec, len(header.Entries))
+	for i, entry := range header.Entries {
+		sections[i] = SectionSpec{Offset: uint64(entry.Offset), Size: uint64(entry.Size), Dest: &bodies[i]}
+	}
+	if err := p.ParseSections(sections...); err != nil {
+		t.Fatal(err)
+	}
+	if string(bodies[0].Text) != "hi" || string(bodies[1].Text) != "x" || string(bodies[2].Text) != "abc" {
+		t.Error("Incorrect sections:", bodies)
+	}
+	if p.Offset() != 7 {
+		t.Error("Parsing sections moved the parser:", p.Offset())
+	}
+}
+
+func TestParseSectionsErrors(t *testing.T) {
+	var bodies [3]SectionBody
+	// The reader doesn't start at the beginning of the data
+	r := bytes.NewReader(append([]byte{0xFF}, containerData...))
+	r.Seek(1, io.SeekStart)
+	p := NewParser(r, LittleEndian, Default)
+	err := p.ParseSections(
+		SectionSpec{Offset: 7, Size: 4, Dest: &bodies[0]},
+		SectionSpec{Offset: 10, Size: 2, Dest: &bodies[1]},
+		SectionSpec{Offset: 12, Size: 3, Dest: &bodies[2]},
+	)
+	var errs ParseErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(errs[0], ErrSizeMismatch) || !errors.Is(errs[1], ErrInvalidLength) {
+		t.Fatal("Incorrect errors:", err)
+	}
+	if errs[1].Offset != 13 {
+		t.Error("Expected the offset of the stream. Got", errs[1].Offset)
+	}
+	if string(bodies[1].Text) != "x" {
+		t.Error("Incorrect section:", bodies[1])
+	}
+
+	p = NewParser(bytes.NewBuffer(containerData), LittleEndian, Default)
+	if err := p.ParseSections(SectionSpec{Offset: 7, Dest: &bodies[0]}); err == nil {
+		t.Error("Expected an error without io.ReaderAt")
+	}
+}
@@ -0,0 +1,178 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var errNotSeekable = errors.New("not seekable")
+var errOutsideRegion = errors.New("outside region")
+
+// Computes the target of an `offset` tag. The tag holds a field, a method or a
+// literal, optionally followed by the base the value is relative to:
+//
+//	`offset:"DataOffset"`              from the start of the stream
+//	`offset:"DataOffset,base=struct"`  from the start of the current struct
+//	`offset:"DataOffset,base=region"`  from the start of the enclosing
+//	                                   size-limited region
+func (p *Parser) parseOffsetTag(offsetkey string, fieldtyp reflect.StructField, ptrval reflect.Value) (uint64, error) {
+	ref, base := offsetkey, "file"
+	if idx := strings.IndexByte(offsetkey, ','); idx >= 0 {
+		ref, base = offsetkey[:idx], offsetkey[idx+1:]
+		if !strings.HasPrefix(base, "base=") {
+			return 0, p.errorf(ErrInvalidTag, nil, "Invalid option in `offset` tag: %v. Expected base=file, base=struct or base=region.", base)
+		}
+		base = base[len("base="):]
+	}
+
+	value, err := p.parseRefTag("offset", ref, fieldtyp, ptrval, -1)
+	if err != nil {
+		return 0, err
+	}
+	switch base {
+	case "file":
+		return value, nil
+	case "struct":
+		return p.stack[len(p.stack)-1].start + value, nil
+	case "region":
+		return p.regionStart + value, nil
+	}
+	return 0, p.errorf(ErrInvalidTag, nil, "Invalid option in `offset` tag: base=%v. Expected base=file, base=struct or base=region.", base)
+}
+
+// Moves the parser to the given offset by seeking the underlying reader.
+// Offsets are counted from where the parse started, like Offset(). Seeking
+// inside a size-limited region keeps the region's bounds intact.
+func (p *Parser) seekTo(target uint64, fieldtyp reflect.StructField) error {
+	if target < p.regionStart {
+		return p.errorf(nil, nil, "Error reading field '%v %v'. Offset %v is outside of the enclosing size-limited region.", fieldtyp.Name, fieldtyp.Type, target)
+	}
+
+	p.alignBits()
+	switch err := seekReader(p.r, int64(target)-int64(p.offset)); err {
+	case nil:
+	case errNotSeekable:
+		return p.errorf(nil, nil, "Error reading field '%v %v'. The `offset` tag requires a reader that implements io.Seeker.", fieldtyp.Name, fieldtyp.Type)
+	case errOutsideRegion:
+		return p.errorf(nil, nil, "Error reading field '%v %v'. Offset %v is outside of the enclosing size-limited region.", fieldtyp.Name, fieldtyp.Type, target)
+	default:
+		return p.wrapError(err)
+	}
+	p.offset = target
+	return nil
+}
+
+// Tell returns the current position in the stream, counted from where the
+// parse started like Offset.
+func (p *Parser) Tell() int64 {
+	return int64(p.offset)
+}
+
+// Seek moves the parser to another position in the stream, for hooks and
+// hand-written parsing code that has to jump around the data. offset is
+// interpreted according to whence, as by io.Seeker: relative to where the
+// parse started, to the current position, or to the end of the data. Inside
+// a size-limited region, as while parsing a field tagged with `size`, the end
+// is that of the region and positions before its start can't be reached.
+//
+// The reader has to implement io.Seeker. Seek returns the new position, as
+// Tell would. A pending partial byte of bit fields is dropped.
+func (p *Parser) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(p.offset)
+	case io.SeekEnd:
+		left, ok := seekableLeft(p.r)
+		if !ok {
+			return p.Tell(), p.errorf(nil, nil, "Unable to seek at offset %v. Seeking requires a reader that implements io.Seeker.", p.offset)
+		}
+		base = int64(p.offset) + left
+	default:
+		return p.Tell(), p.errorf(nil, nil, "Unable to seek at offset %v. Invalid whence %v.", p.offset, whence)
+	}
+
+	target := base + offset
+	if target < 0 || uint64(target) < p.regionStart {
+		return p.Tell(), p.errorf(nil, nil, "Unable to seek to offset %v. It's outside of the data or of the enclosing size-limited region.", target)
+	}
+	if err := p.seekBy(target-int64(p.offset), target); err != nil {
+		return p.Tell(), err
+	}
+	return target, nil
+}
+
+// Seeks the reader by delta bytes, which brings the parser to the given
+// offset.
+func (p *Parser) seekBy(delta, target int64) error {
+	p.alignBits()
+	switch err := seekReader(p.r, delta); err {
+	case nil:
+	case errNotSeekable:
+		return p.errorf(nil, nil, "Unable to seek at offset %v. Seeking requires a reader that implements io.Seeker.", p.offset)
+	case errOutsideRegion:
+		return p.errorf(nil, nil, "Unable to seek to offset %v. It's outside of the data or of the enclosing size-limited region.", target)
+	default:
+		return p.wrapError(err)
+	}
+	p.offset = uint64(target)
+	return nil
+}
+
+// Seeks relative to the current position through any size-limiting wrappers
+// the parser put around the reader.
+func seekReader(r io.Reader, delta int64) error {
+	switch rr := r.(type) {
+	case *io.LimitedReader:
+		if delta > rr.N {
+			return errOutsideRegion
+		}
+		if err := seekReader(rr.R, delta); err != nil {
+			return err
+		}
+		rr.N -= delta
+		return nil
+	case io.Seeker:
+		_, err := rr.Seek(delta, io.SeekCurrent)
+		return err
+	}
+	return errNotSeekable
+}
+
+// Returns how many bytes are left in a reader that can be seeked through with
+// seekReader, or false if it can't be.
+func seekableLeft(r io.Reader) (int64, bool) {
+	switch rr := r.(type) {
+	case *io.LimitedReader:
+		left, ok := seekableLeft(rr.R)
+		return min(left, rr.N), ok
+	case io.Seeker:
+		cur, err := rr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := rr.Seek(0, io.SeekEnd)
+		if _, serr := rr.Seek(cur, io.SeekStart); err != nil || serr != nil {
+			return 0, false
+		}
+		return end - cur, true
+	}
+	return 0, false
+}
+
+// Returns the position of the reader underneath any size-limiting wrappers,
+// or false if it can't seek.
+func readerPosition(r io.Reader) (int64, bool) {
+	for lr, ok := r.(*io.LimitedReader); ok; lr, ok = r.(*io.LimitedReader) {
+		r = lr.R
+	}
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	return pos, err == nil
+}
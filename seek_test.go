@@ -0,0 +1,239 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type DirEntry struct {
+	NameOffset uint8
+	NameLength uint8
+	Name       []byte `len:"NameLength" offset:"NameOffset"`
+}
+
+type Archive struct {
+	Magic     [2]byte
+	DirOffset uint8
+	Count     uint8
+	Dir       []DirEntry `len:"Count" offset:"DirOffset"`
+	Trailer   uint8
+}
+
+var archiveData = []byte{'A', 'R', 9, 2,
+	0xEE,               // Trailer
+	'f', 'o', 'o', 'b', // names
+	5, 3, // first entry
+	8, 1} // second entry
+
+func TestOffsetTag(t *testing.T) {
+	s := Archive{}
+	p := newParserData(archiveData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Dir) != 2 {
+		t.Fatal("Invalid directory length:", len(s.Dir))
+	}
+	if string(s.Dir[0].Name) != "foo" || string(s.Dir[1].Name) != "b" {
+		t.Error("Error parsing names at offsets:", s.Dir)
+	}
+	if s.Trailer != 0xEE {
+		t.Error("Error parsing field after offset field:", s.Trailer)
+	}
+	if p.offset != 5 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOffsetTagLiteral(t *testing.T) {
+	s := struct {
+		Tail [2]byte `offset:"11"`
+		Head uint8
+	}{}
+	p := newParserData(archiveData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(s.Tail == [2]byte{8, 1} && s.Head == 'A') {
+		t.Error("Error parsing field at literal offset:", s)
+	}
+	if p.offset != 1 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOffsetTagNotSeekable(t *testing.T) {
+	s := Archive{}
+	p := NewParser(io.MultiReader(bytes.NewReader(archiveData)), LittleEndian, Default)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Dir []bingo.DirEntry'. The `offset` tag requires a reader that implements io.Seeker." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+type RelativeRecord struct {
+	Kind       uint8
+	NameOffset uint8
+	NameLength uint8
+	Name       []byte `len:"NameLength" offset:"NameOffset,base=struct"`
+}
+
+func TestOffsetTagStructBase(t *testing.T) {
+	data := []byte{0xFF,
+		1, 4, 2, 0xEE, 'h', 'i'}
+	s := struct {
+		Header uint8
+		Record RelativeRecord
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if string(s.Record.Name) != "hi" {
+		t.Error("Error parsing name relative to struct:", s.Record)
+	}
+	if p.offset != 4 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type RegionChunk struct {
+	DataOffset uint8
+	Data       [2]byte `offset:"DataOffset,base=region"`
+	Rest       [2]byte
+	Tail       [2]byte
+}
+
+func TestOffsetTagRegionBase(t *testing.T) {
+	data := []byte{5,
+		3, 0xAA, 0xBB, 'o', 'k'}
+	s := struct {
+		Size  uint8
+		Chunk RegionChunk `size:"Size"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !(string(s.Chunk.Data[:]) == "ok" && s.Chunk.Rest == [2]byte{0xAA, 0xBB} && s.Chunk.Tail == s.Chunk.Data) {
+		t.Error("Error parsing field relative to region:", s.Chunk)
+	}
+	if p.offset != 6 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestOffsetTagOutsideRegion(t *testing.T) {
+	data := []byte{5,
+		7, 0xAA, 0xBB, 'o', 'k', 0, 0}
+	s := struct {
+		Size  uint8
+		Chunk RegionChunk `size:"Size"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Data [2]uint8'. Offset 8 is outside of the enclosing size-limited region." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestOffsetTagEmptyReference(t *testing.T) {
+	s := struct {
+		Value uint8 `offset:",base=struct"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || !errors.Is(err, ErrInvalidTag) || perr.Error() != "Empty `offset` tag on 'Value uint8'. Expected a field, a method or an integer." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestTellSeek(t *testing.T) {
+	p := newParserData(archiveData)
+	if _, err := p.ReadBytes(2); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tell() != 2 {
+		t.Error("Incorrect position:", p.Tell())
+	}
+	for _, test := range []struct {
+		offset int64
+		whence int
+		want   int64
+	}{
+		{5, io.SeekStart, 5},
+		{-2, io.SeekCurrent, 3},
+		{-2, io.SeekEnd, 11},
+	} {
+		if pos, err := p.Seek(test.offset, test.whence); err != nil || pos != test.want || p.Tell() != test.want || p.Offset() != uint64(test.want) {
+			t.Errorf("Incorrect position after Seek(%v, %v): %v, %v", test.offset, test.whence, pos, err)
+		}
+	}
+	if b, err := p.ReadUint8(); err != nil || b != 8 {
+		t.Error("Incorrect byte after seeking:", b, err)
+	}
+	if pos, err := p.Seek(-1, io.SeekStart); err == nil || pos != 12 {
+		t.Error("Expected an error seeking before the start. Got", pos, err)
+	}
+
+	p = NewParser(bytes.NewBuffer(archiveData), LittleEndian, Default)
+	if _, err := p.Seek(1, io.SeekCurrent); err == nil || p.Tell() != 0 {
+		t.Error("Expected an error seeking a reader that can't seek. Got", err)
+	}
+}
+
+type seekBody struct {
+	Last uint8 `parser:"ReadLast"`
+	err  error
+}
+
+func (b *seekBody) ReadLast(p *Parser, last *uint8) error {
+	// The start of the stream is outside of the region
+	_, b.err = p.Seek(0, io.SeekStart)
+	if _, err := p.Seek(-1, io.SeekEnd); err != nil {
+		return err
+	}
+	var err error
+	*last, err = p.ReadUint8()
+	return err
+}
+
+func TestSeekInRegion(t *testing.T) {
+	var s struct {
+		Size  uint8
+		Body  seekBody `size:"Size"`
+		After uint8
+	}
+	p := newParserData([]byte{3, 'a', 'b', 'c', 9})
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Body.Last != 'c' || s.After != 9 {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+	if s.Body.err == nil {
+		t.Error("Expected an error seeking outside of the region")
+	}
+}
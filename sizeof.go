@@ -0,0 +1,408 @@
+package bingo
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Sizes of the encodings of the `time` tag
+var timeSizes = map[string]uint64{"unix32": 4, "unix64ms": 8, "filetime": 8, "dosdatetime": 4}
+
+// SizeOf returns the number of bytes v, a struct or a pointer to one, takes
+// up in the stream by the rules EmitReadStruct parses it with, for making a
+// buffer of the right size or filling in a length before writing it out.
+//
+// The size is worked out from the values of v's fields. Slices, strings and
+// maps take up what their contents do, whatever the fields named by their
+// `len` or `size` tags hold, and so do structs with a `size` tag; prefixes,
+// terminators, padding and alignment are added on as the tags ask. `if`,
+// `switch` and `skip` tags are evaluated on v's fields as they would be
+// while parsing, calling the methods they name with a parser that reads
+// nothing. Fields located elsewhere with an `offset` tag, and Refs, take up
+// nothing where they're declared.
+//
+// Fields whose size can't be told from their value, those with a `parser`,
+// `demux` or `discard` tag, are reported as errors.
+func SizeOf(v interface{}) (int, error) {
+	ptrval := reflect.ValueOf(v)
+	if ptrval.IsValid() && ptrval.Kind() != reflect.Ptr {
+		// Methods named by tags take a pointer to the struct
+		ptrval = reflect.New(ptrval.Type())
+		ptrval.Elem().Set(reflect.ValueOf(v))
+	}
+	if !ptrval.IsValid() || ptrval.IsNil() || ptrval.Elem().Kind() != reflect.Struct {
+		return 0, &ParseError{kind: ErrUnsupportedType, text: fmt.Sprintf("Invalid argument type %T. Expected a struct or a pointer to one.", v)}
+	}
+	p := NewParser(nil, LittleEndian, Default)
+	if err := p.sizeStruct(ptrval); err != nil {
+		return 0, err
+	}
+	return int(p.offset), nil
+}
+
+// Adds the size of the struct ptrval points to to the parser's offset.
+func (p *Parser) sizeStruct(ptrval reflect.Value) error {
+	val := ptrval.Elem()
+	p.stack = append(p.stack, frame{ptrval: ptrval, start: p.offset, index: p.elemIndex})
+	top := len(p.stack) - 1
+	p.elemIndex = -1
+	plan := planFor(val.Type())
+
+	// bits of the bit fields read since the last byte boundary
+	var nbits uint64
+	var sw *switchState
+	for i := range plan.fields {
+		f := &plan.fields[i]
+		fieldtyp, fieldval := f.StructField, val.Field(i)
+		p.stack[top].field, p.stack[top].fieldType = fieldtyp.Name, fieldtyp.Type
+		if f.skip || len(f.tags["compute"]) > 0 {
+			continue
+		}
+
+		if switchkey := f.tags["switch"]; len(switchkey) > 0 {
+			var err error
+			if sw, err = p.readSwitch(switchkey, fieldtyp, ptrval); err != nil {
+				return err
+			}
+			continue
+		}
+		if casekey := f.tags["case"]; len(casekey) > 0 {
+			selected, err := p.caseSelected(sw, casekey, fieldtyp)
+			if err != nil {
+				return err
+			}
+			if !selected {
+				continue
+			}
+		} else {
+			sw = nil
+		}
+		satisfied, err := p.ifTagSatisfied(fieldtyp, ptrval.Type(), ptrval)
+		if err != nil {
+			return err
+		}
+		if !satisfied {
+			continue
+		}
+
+		if bitskey := f.tags["bits"]; len(bitskey) > 0 {
+			n, err := strconv.ParseUint(bitskey, 0, 8)
+			if err != nil || n == 0 {
+				return p.errorf(ErrInvalidTag, nil, "Invalid value for `bits` tag: %v. Expected a positive integer.", bitskey)
+			}
+			nbits += n
+			continue
+		}
+		p.offset += (nbits + 7) / 8
+		nbits = 0
+
+		if skipkey := f.tags["skip"]; len(skipkey) > 0 {
+			n, err := p.parseRefTag("skip", skipkey, fieldtyp, ptrval, -1)
+			if err != nil {
+				return err
+			}
+			p.offset += n
+			continue
+		}
+		for _, tag := range []string{"parser", "demux", "discard"} {
+			if key := f.tags[tag]; len(key) > 0 && key != "-" {
+				return p.errorf(ErrUnsupportedType, nil, "Unable to tell the size of field '%v %v'. Fields with a `%v` tag are not supported.", fieldtyp.Name, fieldtyp.Type, tag)
+			}
+		}
+		if len(fieldtyp.PkgPath) > 0 {
+			continue
+		}
+		if _, ok := fieldval.Addr().Interface().(refField); ok || len(f.tags["offset"]) > 0 {
+			// Stored elsewhere
+			continue
+		}
+
+		offset := p.offset
+		if err := p.sizeField(fieldval, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		padding, err := p.calculatePadding(fieldtyp, offset)
+		if err != nil {
+			return err
+		}
+		p.offset += padding
+		alignment, err := p.calculateAlignment(fieldtyp)
+		if err != nil {
+			return err
+		}
+		p.offset += alignment
+	}
+	p.offset += (nbits + 7) / 8
+
+	p.stack = p.stack[:top]
+	return nil
+}
+
+// Adds the size of a single field to the parser's offset.
+func (p *Parser) sizeField(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	if lazy, ok := fieldval.Addr().Interface().(lazyField); ok {
+		p.offset += lazy.Size()
+		return nil
+	}
+	if encoding := getTag(fieldtyp, "varint"); len(encoding) > 0 {
+		return p.sizeVarint(fieldval, fieldtyp, encoding)
+	}
+
+	switch fieldval.Kind() {
+	case reflect.Struct:
+		if fieldval.Type() == timeType {
+			format := getTag(fieldtyp, "time")
+			size, ok := timeSizes[format]
+			if !ok {
+				return p.errorf(ErrInvalidTag, nil, "Invalid value for `time` tag: %v. Expected one of unix32, unix64ms, filetime, dosdatetime.", format)
+			}
+			p.offset += size
+			return nil
+		} else if flagskey := getTag(fieldtyp, "flags"); len(flagskey) > 0 {
+			return p.sizeStorage("flags", flagskey)
+		}
+		return p.sizeRegion(fieldval, fieldtyp)
+
+	case reflect.Slice:
+		return p.sizeSlice(fieldval, fieldtyp, ptrval)
+
+	case reflect.String:
+		n := uint64(fieldval.Len())
+		if termkey := getTag(fieldtyp, "terminator"); len(termkey) > 0 {
+			term, err := p.parseTerminator(termkey)
+			if err != nil {
+				return err
+			}
+			p.offset += n + uint64(len(term))
+			return nil
+		}
+		if prefixkey := getTag(fieldtyp, "lenprefix"); len(prefixkey) > 0 {
+			if err := p.sizeLengthPrefix(prefixkey, n, fieldtyp); err != nil {
+				return err
+			}
+		} else if len(getTag(fieldtyp, "len")) == 0 {
+			return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+		}
+		p.offset += n
+		return nil
+
+	case reflect.Map:
+		iter := fieldval.MapRange()
+		for iter.Next() {
+			if err := p.sizeElem(iter.Key(), fieldtyp); err != nil {
+				return err
+			}
+			if err := p.sizeElem(iter.Value(), fieldtyp); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Interface:
+		elem := fieldval.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if !elem.IsValid() {
+			return nil
+		}
+		if elem.Kind() != reflect.Struct {
+			return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type %v not supported.", fieldtyp.Name, fieldtyp.Type, elem.Type())
+		}
+		return p.sizeRegion(elem, fieldtyp)
+
+	case reflect.Func:
+		return nil
+
+	case reflect.Bool, reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type not supported.", fieldtyp.Name, fieldtyp.Type)
+
+	case reflect.Int64:
+		if fieldval.Type() == durationType {
+			storage := "int64"
+			if _, after, ok := strings.Cut(getTag(fieldtyp, "unit"), ","); ok {
+				storage = after
+			}
+			return p.sizeStorage("unit", storage)
+		}
+	}
+	return p.sizeElem(fieldval, fieldtyp)
+}
+
+// Adds the size of a struct field or of the value of an interface field,
+// which is a region of its own if the field has a `size` tag.
+func (p *Parser) sizeRegion(val reflect.Value, fieldtyp reflect.StructField) error {
+	if !val.CanAddr() {
+		ptrval := reflect.New(val.Type())
+		ptrval.Elem().Set(val)
+		val = ptrval.Elem()
+	}
+	tmp_start := p.regionStart
+	if len(getTag(fieldtyp, "size")) > 0 {
+		p.regionStart = p.offset
+	}
+	err := p.sizeStruct(val.Addr())
+	p.regionStart = tmp_start
+	return err
+}
+
+// Adds the size of a slice field.
+func (p *Parser) sizeSlice(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	n := fieldval.Len()
+	elemtyp := fieldval.Type().Elem()
+	if termkey := getTag(fieldtyp, "terminator"); len(termkey) > 0 {
+		term, err := p.parseTerminator(termkey)
+		if err != nil {
+			return err
+		}
+		if elemtyp.Kind() == reflect.Uint8 {
+			p.offset += uint64(n + len(term))
+			return nil
+		}
+		// the terminator takes up one element
+		n++
+		fieldval = reflect.Append(fieldval, reflect.Zero(elemtyp))
+	}
+	if prefixkey := getTag(fieldtyp, "lenprefix"); len(prefixkey) > 0 {
+		if err := p.sizeLengthPrefix(prefixkey, uint64(n), fieldtyp); err != nil {
+			return err
+		}
+	}
+	if elemsizekey := getTag(fieldtyp, "elemsize"); len(elemsizekey) > 0 {
+		for i := 0; i < n; i++ {
+			size, err := p.parseLengthTag("elemsize", elemsizekey, fieldtyp, ptrval, i)
+			if err != nil {
+				return err
+			}
+			p.offset += size
+		}
+		return nil
+	}
+	if elemtyp.Kind() == reflect.Uint8 {
+		p.offset += uint64(n)
+		return nil
+	}
+
+	tmp_start := p.regionStart
+	if len(getTag(fieldtyp, "size")) > 0 {
+		p.regionStart = p.offset
+	}
+	defer func() { p.regionStart = tmp_start }()
+	for i := 0; i < n; i++ {
+		p.elemIndex = i
+		err := p.sizeElem(fieldval.Index(i), fieldtyp)
+		p.elemIndex = -1
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adds the size of an element of a slice or a map, or of a field read as
+// plain fixed-size data.
+func (p *Parser) sizeElem(val reflect.Value, fieldtyp reflect.StructField) error {
+	if val.Kind() == reflect.Struct {
+		if !val.CanAddr() {
+			ptrval := reflect.New(val.Type())
+			ptrval.Elem().Set(val)
+			val = ptrval.Elem()
+		}
+		return p.sizeStruct(val.Addr())
+	}
+	size := fixedSize(val.Type())
+	if size < 0 {
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. Type %v not supported.", fieldtyp.Name, fieldtyp.Type, val.Type())
+	}
+	p.offset += uint64(size)
+	return nil
+}
+
+// Adds the size of an integer of the type named in a tag.
+func (p *Parser) sizeStorage(tag, storage string) error {
+	typ, ok := storageTypes[storage]
+	if !ok {
+		return p.errorf(ErrInvalidTag, nil, "Invalid storage type in `%v` tag: %v. Expected a fixed-size integer type.", tag, storage)
+	}
+	p.offset += uint64(typ.Size())
+	return nil
+}
+
+// Adds the size of the prefix holding the given length in the encoding
+// named by a `lenprefix` tag, once the length is adjusted back to what is
+// stored by the `lenadjust` tag.
+func (p *Parser) sizeLengthPrefix(prefixkey string, length uint64, fieldtyp reflect.StructField) error {
+	stored := int64(length)
+	if adjkey := getTag(fieldtyp, "lenadjust"); len(adjkey) > 0 {
+		adj, err := strconv.ParseInt(adjkey, 0, 0)
+		if err != nil {
+			return p.errorf(ErrInvalidTag, nil, "Invalid value for `lenadjust` tag: %v. Expected a signed integer.", adjkey)
+		}
+		if stored -= adj; stored < 0 {
+			return p.errorf(ErrInvalidLength, nil, "Error reading field '%v %v'. Length %v adjusted by %v is negative.", fieldtyp.Name, fieldtyp.Type, length, -adj)
+		}
+	}
+	switch prefixkey {
+	case "varint":
+		p.offset += uvarintSize(uint64(stored))
+	case "gitofs":
+		p.offset += gitOffsetSize(uint64(stored))
+	case "ber":
+		p.offset++
+		if stored >= 0x80 {
+			p.offset += uint64(bits.Len64(uint64(stored))+7) / 8
+		}
+	default:
+		return p.sizeStorage("lenprefix", prefixkey)
+	}
+	return nil
+}
+
+// Adds the size of an integer field with a `varint` tag.
+func (p *Parser) sizeVarint(fieldval reflect.Value, fieldtyp reflect.StructField, encoding string) error {
+	var value uint64
+	switch fieldval.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fieldval.Int() < 0 {
+			return p.errorf(nil, nil, "Error sizing varint for '%v %v'. Value %v is negative.", fieldtyp.Name, fieldtyp.Type, fieldval.Int())
+		}
+		value = uint64(fieldval.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = fieldval.Uint()
+	default:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. The `varint` tag is only supported on integers.", fieldtyp.Name, fieldtyp.Type)
+	}
+	switch encoding {
+	case "leb128":
+		p.offset += uvarintSize(value)
+	case "gitofs":
+		p.offset += gitOffsetSize(value)
+	default:
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `varint` tag: %v. Expected leb128 or gitofs.", encoding)
+	}
+	return nil
+}
+
+// Returns the number of bytes of the unsigned LEB128 encoding of v.
+func uvarintSize(v uint64) uint64 {
+	n := uint64(1)
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// Returns the number of bytes of the git packfile offset encoding of v, in
+// which each byte after the first also adds one to what the bytes before it
+// stand for.
+func gitOffsetSize(v uint64) uint64 {
+	n := uint64(1)
+	for v >>= 7; v != 0; v >>= 7 {
+		v--
+		n++
+	}
+	return n
+}
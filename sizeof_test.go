@@ -0,0 +1,96 @@
+package bingo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type sizedRecord struct {
+	Flags   uint8 `bits:"3"`
+	Kind    uint8 `bits:"4"`
+	Count   uint16
+	Name    string    `lenprefix:"varint"`
+	Label   string    `terminator:"0x00"`
+	Values  []uint16  `len:"Count" pad:"4"`
+	Extra   uint32    `if:"Kind == 2"`
+	Missing uint32    `if:"Kind == 3"`
+	_       Skip      `skip:"2"`
+	Big     uint64    `varint:"leb128"`
+	Stamp   time.Time `time:"unix32"`
+	Size    uint8
+	Items   []blob `size:"Size"`
+	Tail    uint8  `align:"4"`
+}
+
+var sizedRecordData = []byte{
+	0x24, // Flags, Kind
+	2, 0, // Count
+	2, 'h', 'i', // Name
+	'a', 0, // Label
+	1, 0, 2, 0, // Values
+	7, 0, 0, 0, // Extra
+	0xFF, 0xFF, // skipped
+	0x80, 0x01, // Big
+	1, 0, 0, 0, // Stamp
+	9, 3, 'a', 'b', 'c', 2, 0xAA, 0xBB, 1, 2, // Size, Items
+	5, 0, // Tail, aligned
+}
+
+func TestSizeOf(t *testing.T) {
+	var s sizedRecord
+	p := newParserData(sizedRecordData)
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Tail != 5 || p.Offset() != uint64(len(sizedRecordData)) {
+		t.Fatalf("Incorrect struct: %+v at offset %v", s, p.Offset())
+	}
+	for _, v := range []interface{}{s, &s} {
+		if size, err := SizeOf(v); err != nil || size != len(sizedRecordData) {
+			t.Errorf("Incorrect size of %T: %v, %v", v, size, err)
+		}
+	}
+
+	// The size follows the values of the fields
+	s.Name, s.Label = string(make([]byte, 200)), "ab"
+	s.Values = s.Values[:1]
+	s.Kind = 3
+	size, err := SizeOf(&s)
+	if want := len(sizedRecordData) + 200; err != nil || size != want {
+		t.Errorf("Incorrect size: %v, %v. Expected %v", size, err, want)
+	}
+}
+
+func TestSizeOfErrors(t *testing.T) {
+	if _, err := SizeOf(3); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an unsupported type error. Got", err)
+	}
+	var custom struct {
+		Name UnicodeString `parser:"ParseUnicodeString"`
+	}
+	if _, err := SizeOf(custom); !errors.Is(err, ErrUnsupportedType) {
+		t.Error("Expected an unsupported type error. Got", err)
+	}
+}
+
+func TestVarintSizes(t *testing.T) {
+	for _, test := range []struct {
+		value         uint64
+		leb128, gitof uint64
+	}{
+		{0, 1, 1},
+		{0x7F, 1, 1},
+		{0x80, 2, 2},
+		{0x407F, 3, 2},
+		{0x4080, 3, 3},
+		{1<<64 - 1, 10, 10},
+	} {
+		if n := uvarintSize(test.value); n != test.leb128 {
+			t.Errorf("Incorrect LEB128 size of %#x: %v", test.value, n)
+		}
+		if n := gitOffsetSize(test.value); n != test.gitof {
+			t.Errorf("Incorrect git offset size of %#x: %v", test.value, n)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package bingo
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// SetSlogLogger makes the parser emit structured records to l, in addition
+// to what goes to the logger given to SetLogger. Each field read is logged at
+// the debug level, hooks being called at the debug level too, and warnings at
+// the warn level. Records carry the struct type, the field name, the offset
+// and the nesting depth as the attributes "struct", "field", "offset" and
+// "depth". Pass nil to turn it off.
+func (p *Parser) SetSlogLogger(l *slog.Logger) {
+	p.slog = l
+}
+
+// Attributes locating a record at the field the parser is at
+func (p *Parser) slogAttrs(offset uint64) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 4)
+	if n := len(p.stack); n > 0 {
+		top := p.stack[n-1]
+		attrs = append(attrs,
+			slog.String("struct", top.ptrval.Elem().Type().String()),
+			slog.String("field", top.field))
+	}
+	return append(attrs, slog.Uint64("offset", uint64(offset)), slog.Int("depth", p.depth))
+}
+
+func (p *Parser) slogEnabled(level slog.Level) bool {
+	return p.slog != nil && p.slog.Enabled(context.Background(), level)
+}
+
+// Logs a field that was just read, starting at offset.
+func (p *Parser) slogField(offset uint64, fieldval reflect.Value) {
+	if !p.slogEnabled(slog.LevelDebug) {
+		return
+	}
+	attrs := append(p.slogAttrs(offset),
+		slog.String("type", fieldval.Type().String()),
+		slog.String("value", formatTraceValue(fieldval)))
+	p.slog.LogAttrs(context.Background(), slog.LevelDebug, "bingo: read field", attrs...)
+}
+
+// Logs a call to a method defined by the user.
+func (p *Parser) logHook(name string, typ reflect.Type) {
+	p.l.Printf(">>Calling %v on %v\n", name, typ)
+	if p.slogEnabled(slog.LevelDebug) {
+		attrs := append(p.slogAttrs(p.offset), slog.String("method", name))
+		p.slog.LogAttrs(context.Background(), slog.LevelDebug, "bingo: calling hook", attrs...)
+	}
+}
@@ -0,0 +1,63 @@
+package bingo
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type SlogHeader struct {
+	Version uint8 `warn:"!=1"`
+	Inner   struct {
+		Count uint16
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	s := SlogHeader{}
+	p := newParserData([]byte{1, 2, 0})
+	var buf bytes.Buffer
+	p.SetSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`level=WARN msg="Unexpected value in 'Version uint8' at offset 0: expected != 1, got 1." struct=bingo.SlogHeader field=Version offset=1 depth=1`,
+		`level=DEBUG msg="bingo: read field" struct=bingo.SlogHeader field=Version offset=0 depth=1 type=uint8 value=1`,
+		`level=DEBUG msg="bingo: read field" struct="struct { Count uint16 }" field=Count offset=1 depth=2 type=uint16 value=2`,
+		`level=DEBUG msg="bingo: read field" struct=bingo.SlogHeader field=Inner offset=1 depth=1 type="struct { Count uint16 }" value={2}`,
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %v records. Got:\n%v", len(expected), buf.String())
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Incorrect record %v:\n%v\nexpected\n%v", i, line, expected[i])
+		}
+	}
+}
+
+func TestSlogLoggerLevel(t *testing.T) {
+	s := SlogHeader{}
+	p := newParserData([]byte{1, 2, 0})
+	var buf bytes.Buffer
+	p.SetSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1 || !strings.Contains(buf.String(), "level=WARN") {
+		t.Errorf("Expected a single warning. Got:\n%v", buf.String())
+	}
+}
@@ -0,0 +1,178 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Tags of other packages commonly found next to ours. They are never taken
+// for misspellings.
+var foreignTags = map[string]bool{
+	"json": true, "xml": true, "yaml": true, "toml": true, "db": true,
+	"validate": true, "mapstructure": true, "protobuf": true, "msgpack": true,
+	"form": true, "env": true,
+}
+
+var (
+	intKinds  = []reflect.Kind{reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64}
+	bitsKinds = append([]reflect.Kind{reflect.Bool}, intKinds...)
+)
+
+// Kinds of fields the tags that depend on the field's type make sense on.
+var tagKinds = map[string][]reflect.Kind{
+	"len":        {reflect.Slice, reflect.String, reflect.Map},
+	"lenprefix":  {reflect.Slice, reflect.String},
+	"size":       {reflect.Struct, reflect.Slice, reflect.Interface},
+	"elemsize":   {reflect.Slice},
+	"terminator": {reflect.Slice, reflect.String},
+	"until":      {reflect.Slice},
+	"bits":       bitsKinds,
+	"varint":     intKinds,
+}
+
+// Describes what's wrong with the tags of a field that the parser doesn't
+// know or that don't apply to the field's type, or returns "" if nothing is.
+// Enforced in Strict mode, where a misspelled tag shouldn't silently change
+// how a struct is parsed, and by Compile.
+//
+// Since other packages put their own tags on the same fields, a separate tag
+// is only rejected when it looks like a misspelling of one of ours, such as
+// `lenght` or `Size`. Inside a `bingo` tag every option must be known.
+func tagProblem(fieldtyp reflect.StructField) string {
+	for _, key := range tagKeys(fieldtyp.Tag) {
+		if key == "bingo" {
+			if combined := fieldtyp.Tag.Get("bingo"); combined != "-" {
+				for opt := range parseBingoTag(combined) {
+					if !knownTags[opt] {
+						return fmt.Sprintf("Unknown option '%v' in `bingo` tag of '%v %v'.", opt, fieldtyp.Name, fieldtyp.Type)
+					}
+				}
+			}
+		} else if !knownTags[key] && !foreignTags[key] {
+			if similar := similarTag(key); len(similar) > 0 {
+				return fmt.Sprintf("Unknown tag `%v` on '%v %v'. Did you mean `%v`?", key, fieldtyp.Name, fieldtyp.Type, similar)
+			}
+		}
+	}
+
+	if len(getTag(fieldtyp, "parser")) > 0 || len(getTag(fieldtyp, "demux")) > 0 {
+		// custom parsers and demux markers use tags their own way
+		return ""
+	}
+	kind := fieldtyp.Type.Kind()
+tags:
+	for tag, kinds := range tagKinds {
+		if len(getTag(fieldtyp, tag)) == 0 {
+			continue
+		}
+		for _, k := range kinds {
+			if k == kind {
+				continue tags
+			}
+		}
+		return fmt.Sprintf("Error reading field '%v %v'. The `%v` tag doesn't apply to fields of kind %v.", fieldtyp.Name, fieldtyp.Type, tag, kind)
+	}
+	return ""
+}
+
+// Lists the keys of a struct tag in order, following the conventional
+// key:"value" syntax that StructTag.Get understands.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Skip the quoted value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		if _, err := strconv.Unquote(string(tag[:i+1])); err != nil {
+			break
+		}
+		tag = tag[i+1:]
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// Returns the known tag an unknown one is probably a misspelling of: the same
+// name in a different case, a known name followed by extra letters, or one a
+// couple of edits away.
+func similarTag(key string) string {
+	lower := strings.ToLower(key)
+	if knownTags[lower] {
+		return lower
+	}
+	best := ""
+	for known := range knownTags {
+		if len(known) >= 3 && strings.HasPrefix(lower, known) && len(known) > len(best) {
+			best = known
+		}
+	}
+	if len(best) > 0 {
+		return best
+	}
+
+	bestDist := 0
+	for known := range knownTags {
+		maxDist := 2
+		if len(known) <= 4 {
+			maxDist = 1
+		}
+		d := editDistance(lower, known)
+		if d > maxDist {
+			continue
+		}
+		if len(best) == 0 || d < bestDist || d == bestDist && known < best {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// Levenshtein distance between two ASCII strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,75 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func newStrictParser(data []byte) *Parser {
+	return NewParser(bytes.NewReader(data), LittleEndian, Strict)
+}
+
+func TestStrictUnknownTag(t *testing.T) {
+	tests := []struct {
+		data    interface{}
+		message string
+	}{
+		{&struct {
+			Count uint8
+			Data  []byte `lenght:"Count"`
+		}{}, "Unknown tag `lenght` on 'Data []uint8'. Did you mean `len`?"},
+		{&struct {
+			Size uint8
+			Body struct{ A uint8 } `Size:"Size"`
+		}{}, "Unknown tag `Size` on 'Body struct { A uint8 }'. Did you mean `size`?"},
+		{&struct {
+			Flags uint8 `asert:"<4"`
+		}{}, "Unknown tag `asert` on 'Flags uint8'. Did you mean `assert`?"},
+		{&struct {
+			Count uint8
+			Data  []byte `bingo:"len=Count,pading=4"`
+		}{}, "Unknown option 'pading' in `bingo` tag of 'Data []uint8'."},
+		{&struct {
+			Count uint8 `len:"4"`
+		}{}, "Error reading field 'Count uint8'. The `len` tag doesn't apply to fields of kind uint8."},
+	}
+	for _, test := range tests {
+		p := newStrictParser([]byte{1, 2, 3, 4, 5, 6})
+
+		if err := p.EmitReadStruct(test.data); err != nil {
+			if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrInvalidTag) || perr.Error() != test.message {
+				t.Error("Incorrect error:", err)
+			}
+		} else {
+			t.Error("Expected an error:", test.message)
+		}
+	}
+}
+
+func TestStrictForeignTags(t *testing.T) {
+	s := struct {
+		Count uint8  `json:"count" note:"anything"`
+		Data  []byte `len:"Count" validate:"required"`
+	}{}
+	p := newStrictParser([]byte{2, 'a', 'b'})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+	if string(s.Data) != "ab" {
+		t.Error("Error parsing field:", s.Data)
+	}
+}
+
+func TestUnknownTagNonStrict(t *testing.T) {
+	s := struct {
+		Count uint8
+		Data  []byte `lenght:"Count"`
+	}{}
+	p := newParserData([]byte{2})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+}
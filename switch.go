@@ -0,0 +1,56 @@
+package bingo
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// State of a `switch` declaration while the `case` fields following it are
+// being read.
+type switchState struct {
+	value   uint64
+	matched bool
+}
+
+// Evaluates the reference in a `switch` tag. The tag goes on a marker field
+// that precedes a group of fields tagged with `case`, of which only the ones
+// listing the switch value are read:
+//
+//	Kind uint8
+//	_    struct{} `switch:"Kind"`
+//	Text string   `case:"1" lenprefix:"uint8"`
+//	Size uint32   `case:"2,3"`
+//	Raw  []byte   `case:"default" size:"<rest>"`
+//
+// The group ends with the first field without a `case` tag. A "default" case
+// is read when no case before it matched.
+func (p *Parser) readSwitch(switchkey string, fieldtyp reflect.StructField, ptrval reflect.Value) (*switchState, error) {
+	value, err := p.parseRefTag("switch", switchkey, fieldtyp, ptrval, -1)
+	if err != nil {
+		return nil, err
+	}
+	return &switchState{value: value}, nil
+}
+
+// Reports whether a field with the given `case` tag is selected. Raises an
+// error for case fields outside of a switch group.
+func (p *Parser) caseSelected(sw *switchState, casekey string, fieldtyp reflect.StructField) (bool, error) {
+	if sw == nil {
+		return false, p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. `case` tag without a preceding `switch`.", fieldtyp.Name, fieldtyp.Type)
+	}
+	if casekey == "default" {
+		return !sw.matched, nil
+	}
+	for _, lit := range strings.Split(casekey, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(lit), 0, 64)
+		if err != nil {
+			return false, p.errorf(ErrInvalidTag, nil, "Invalid value for `case` tag: %v. Expected integers or \"default\".", casekey)
+		}
+		if n == sw.value {
+			sw.matched = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,57 @@
+package bingo
+
+import (
+	"testing"
+)
+
+type switchRecord struct {
+	Kind   uint8
+	_      struct{} `switch:"Kind"`
+	Length uint8    `case:"1"`
+	Text   string   `case:"1" len:"Length"`
+	Size   uint16   `case:"2,3" default:"0xFFFF"`
+	Raw    []byte   `case:"default" size:"2"`
+	End    uint8
+}
+
+func TestSwitchCase(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want switchRecord
+	}{
+		{[]byte{1, 2, 'h', 'i', 9}, switchRecord{Kind: 1, Length: 2, Text: "hi", Size: 0xFFFF, End: 9}},
+		{[]byte{3, 0x34, 0x12, 9}, switchRecord{Kind: 3, Size: 0x1234, End: 9}},
+		{[]byte{7, 'a', 'b', 9}, switchRecord{Kind: 7, Size: 0xFFFF, Raw: []byte("ab"), End: 9}},
+	}
+	for _, test := range tests {
+		s := switchRecord{}
+		p := newParserData(test.data)
+
+		if err := p.EmitReadStruct(&s); err != nil {
+			t.Error(err)
+		}
+
+		if s.Kind != test.want.Kind || s.Length != test.want.Length || s.Text != test.want.Text ||
+			s.Size != test.want.Size || string(s.Raw) != string(test.want.Raw) || s.End != test.want.End {
+			t.Error("Error parsing union:", s)
+		}
+		if p.offset != uint64(len(test.data)) {
+			t.Error("Invalid offset:", p.offset)
+		}
+	}
+}
+
+func TestCaseWithoutSwitch(t *testing.T) {
+	s := struct {
+		Size uint16 `case:"1"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Size uint16'. `case` tag without a preceding `switch`." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
@@ -0,0 +1,26 @@
+// Package analyzer runs tagcheck as an analysis.Analyzer of
+// golang.org/x/tools, so that the bingo tags of a package can be checked by
+// any driver of analyzers, such as singlechecker, unitchecker for go vet, or
+// gopls. It lives apart from tagcheck to keep the dependency on x/tools out of
+// programs that only call Check.
+package analyzer
+
+import (
+	"github.com/alco/bingo/tagcheck"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports the problems tagcheck.Check finds with the bingo tags of
+// the struct types of a package.
+var Analyzer = &analysis.Analyzer{
+	Name: "bingotags",
+	Doc:  "check the bingo tags of struct fields\n\nReports references to fields and methods that don't exist or to fields that aren't integers, length tags that conflict with each other, and fields of types bingo can't parse.",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, d := range tagcheck.Check(pass.Files, pass.TypesInfo) {
+		pass.Reportf(d.Pos, "%s", d.Message)
+	}
+	return nil, nil
+}
@@ -0,0 +1,23 @@
+//go:build ignore
+
+// Generates tables.go from the tables of tags of the bingo package, so that
+// tagcheck knows the same tags as the parser. Run with go generate.
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	src, err := os.ReadFile("../tagtables.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	out := "// Code generated by gentables.go from ../tagtables.go. DO NOT EDIT.\n\n" +
+		strings.Replace(string(src), "package bingo", "package tagcheck", 1)
+	if err := os.WriteFile("tables.go", []byte(out), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,287 @@
+// Package tagcheck checks the bingo tags of the struct types in Go source
+// code, so that the mistakes bingo would only report while parsing show up
+// when the code is compiled or vetted: references to fields and methods that
+// don't exist or to fields that aren't integers, length tags that conflict
+// with each other, and fields of types bingo can't parse.
+//
+// Check works on the syntax trees and type information of a package. The
+// analyzer subpackage runs it as an analysis.Analyzer of golang.org/x/tools,
+// which provides both, so that it can be run by go vet or gopls.
+//
+// Only structs with at least one bingo tag are checked. References to
+// enclosing structs, with "^.", are resolved while parsing and aren't
+// covered.
+package tagcheck
+
+//go:generate go run gentables.go
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a problem with the tags of a struct field.
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+// Check reports the problems with the bingo tags of the struct types in
+// files, whose types are described by info.
+func Check(files []*ast.File, info *types.Info) []Diagnostic {
+	// Named struct types, whose methods tags may refer to
+	named := make(map[*ast.StructType]types.Type)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					if obj := info.Defs[spec.Name]; obj != nil {
+						named[st] = obj.Type()
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	var diags []Diagnostic
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				diags = append(diags, checkStruct(st, named[st], info)...)
+			}
+			return true
+		})
+	}
+	return diags
+}
+
+// A field of a struct along with its tags, the separate ones and those of
+// the `bingo` tag merged
+type field struct {
+	pos  token.Pos
+	v    *types.Var
+	tags map[string]string
+}
+
+func checkStruct(st *ast.StructType, namedtyp types.Type, info *types.Info) []Diagnostic {
+	structtyp, ok := info.TypeOf(st).(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var fields []field
+	tagged := false
+	for i := 0; i < structtyp.NumFields(); i++ {
+		tags := parseTags(reflect.StructTag(structtyp.Tag(i)))
+		if _, ignored := tags["-"]; ignored {
+			continue
+		}
+		for key := range tags {
+			tagged = tagged || knownTags[key]
+		}
+		fields = append(fields, field{pos: structtyp.Field(i).Pos(), v: structtyp.Field(i), tags: tags})
+	}
+	if !tagged {
+		return nil
+	}
+
+	var methods *types.MethodSet
+	if namedtyp != nil {
+		methods = types.NewMethodSet(types.NewPointer(namedtyp))
+	}
+	hasMethod := func(name string) bool {
+		return methods != nil && methods.Lookup(nil, name) != nil
+	}
+
+	var diags []Diagnostic
+	for _, f := range fields {
+		blank := f.v.Name() == "_"
+		if !f.v.Exported() && !blank {
+			continue
+		}
+		report := func(format string, args ...interface{}) {
+			diags = append(diags, Diagnostic{Pos: f.pos, Message: fmt.Sprintf(format, args...)})
+		}
+		has := func(tag string) bool {
+			return len(f.tags[tag]) > 0
+		}
+		article := func(tag string) string {
+			if strings.ContainsRune("aeiou", rune(tag[0])) {
+				return "an"
+			}
+			return "a"
+		}
+
+		for _, tag := range methodTags {
+			name, _, _ := strings.Cut(f.tags[tag], ",")
+			name = strings.TrimSuffix(name, "()")
+			if len(name) == 0 || tag == "demux" && name == "-" {
+				continue
+			}
+			if !hasMethod(name) {
+				report("Method '%v()' not found. Referenced from %v `%v` tag on '%v'.", name, article(tag), tag, f.v.Name())
+			}
+		}
+		if has("parser") || has("demux") {
+			// custom parsers and demux markers use tags their own way
+			continue
+		}
+
+		typ := f.v.Type().Underlying()
+		if _, ok := typ.(*types.Slice); ok {
+			if has("len") && has("size") {
+				report("Can't have both `len` and `size` tags on '%v'.", f.v.Name())
+			}
+			if has("lenprefix") && (has("len") || has("size")) {
+				report("Can't combine `lenprefix` with `len` or `size` tags on '%v'.", f.v.Name())
+			}
+		}
+		// Blank fields are markers and computed fields aren't read, whatever
+		// their type
+		if problem := unsupported(f.v.Type(), f.tags); len(problem) > 0 && !blank && !has("compute") {
+			report("Can't parse '%v %v'. %v", f.v.Name(), f.v.Type(), problem)
+		}
+
+		for _, tag := range refTags {
+			ref := f.tags[tag]
+			if tag == "offset" {
+				ref, _, _ = strings.Cut(ref, ",")
+			}
+			if len(ref) == 0 || ref == "<inf>" || ref == "<rest>" || strings.HasPrefix(ref, "^.") {
+				continue
+			}
+			if c := ref[0]; '0' <= c && c <= '9' {
+				if _, err := strconv.ParseUint(ref, 0, 64); err != nil {
+					report("Invalid value for `%v` tag on '%v': %v. Expected an integer.", tag, f.v.Name(), ref)
+				}
+				continue
+			}
+			if name, ok := strings.CutSuffix(ref, "()"); ok {
+				if !hasMethod(name) {
+					report("Method '%v()' not found. Referenced from %v `%v` tag on '%v'.", name, article(tag), tag, f.v.Name())
+				}
+				continue
+			}
+			reftyp := fieldTypeByPath(structtyp, ref)
+			if reftyp == nil {
+				report("Field '%v' not found. Referenced from %v `%v` tag on '%v'.", ref, article(tag), tag, f.v.Name())
+			} else if !isInteger(reftyp) {
+				report("Field '%v' referenced from %v `%v` tag on '%v' is a %v. Expected an integer.", ref, article(tag), tag, f.v.Name(), reftyp)
+			}
+		}
+	}
+	return diags
+}
+
+// Describes why bingo can't parse a field of type typ with the given tags,
+// or returns "" if it can.
+func unsupported(typ types.Type, tags map[string]string) string {
+	if _, ok := typ.(*types.TypeParam); ok {
+		// known once instantiated
+		return ""
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Pointer:
+		return "Pointer fields are not supported."
+	case *types.Chan:
+		return "Type not supported."
+	case *types.Basic:
+		if u.Kind() == types.UnsafePointer {
+			return "Type not supported."
+		}
+		if u.Info()&types.IsBoolean != 0 && len(tags["bits"]) == 0 && len(tags["bit"]) == 0 {
+			return "Bool fields require a `bits` tag."
+		}
+	case *types.Map:
+		if len(tags["len"]) == 0 {
+			return "Map fields require a `len` tag."
+		}
+	case *types.Interface:
+		if len(tags["type"]) == 0 {
+			return "Interface fields require a `type` tag."
+		}
+	}
+	return ""
+}
+
+// Looks up the type of a field by a dot-separated path through nested
+// structs, or returns nil if there's no such field.
+func fieldTypeByPath(structtyp *types.Struct, path string) types.Type {
+	var typ types.Type = structtyp
+	for more := true; more; {
+		var name string
+		name, path, more = strings.Cut(path, ".")
+		st, ok := typ.Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		typ = nil
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == name {
+				typ = st.Field(i).Type()
+				break
+			}
+		}
+		if typ == nil {
+			return nil
+		}
+	}
+	return typ
+}
+
+func isInteger(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0 && basic.Kind() != types.Uintptr
+}
+
+// Returns the tags of a field, with the options of its `bingo` tag merged
+// in. A separate tag takes precedence over the same option in the `bingo`
+// tag, as it does when parsing.
+func parseTags(tag reflect.StructTag) map[string]string {
+	tags := make(map[string]string)
+	if combined, ok := tag.Lookup("bingo"); ok {
+		if combined == "-" {
+			tags["-"] = ""
+			return tags
+		}
+		var key string
+		for _, part := range strings.Split(combined, ",") {
+			// Options of the option before, as in "offset=Off,base=struct", are
+			// part of its value
+			if name, value, ok := strings.Cut(part, "="); ok && isOptionName(name) && !slices.Contains(tagOptions[key], name) {
+				key = name
+				tags[key] = value
+			} else if flagTags[part] {
+				key = ""
+				tags[part] = "true"
+			} else if len(key) > 0 {
+				tags[key] += "," + part
+			}
+		}
+	}
+	for key := range knownTags {
+		if value, ok := tag.Lookup(key); ok {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+func isOptionName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
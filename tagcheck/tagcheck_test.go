@@ -0,0 +1,115 @@
+package tagcheck
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"testing"
+)
+
+const source = `package records
+
+type Header struct {
+	Magic [4]byte
+	Count uint16
+}
+
+type Record struct {
+	Header  Header
+	Size    uint8
+	Name    string
+	Entries []uint32 ` + "`len:\"Header.Count\"`" + `
+	Body    []byte   ` + "`size:\"Size\" pad:\"4\"`" + `
+	Extra   []byte   ` + "`bingo:\"len=BodyLen(),if=Size > 2\"`" + `
+	_       struct{} ` + "`switch:\"Size\"`" + `
+	Text    string   ` + "`case:\"1\" lenprefix:\"uint8\"`" + `
+	Valid   bool     ` + "`compute:\"IsValid\"`" + `
+	Tail    []byte   ` + "`size:\"^.Remaining\"`" + `
+	Items   []byte   ` + "`bingo:\"-\"`" + `
+	Rel     []byte   ` + "`bingo:\"len=Size,offset=Size,base=struct\"`" + `
+	next    *Record
+}
+
+func (r *Record) BodyLen(p interface{}) int  { return 0 }
+func (r *Record) IsValid(p interface{}) bool { return true }
+
+type Broken struct {
+	Size    uint8
+	Name    string
+	Data    []byte      ` + "`len:\"Count\"`" + `
+	Both    []byte      ` + "`len:\"1\" size:\"Size\"`" + `
+	Text    []byte      ` + "`size:\"Name\"`" + `
+	Extra   []byte      ` + "`bingo:\"len=Length()\"`" + `
+	Next    *Broken     ` + "`if:\"Size > 1\"`" + `
+	Values  map[int]int ` + "`if:\"Size > 2\"`" + `
+	Flag    bool        ` + "`if:\"Size > 3\"`" + `
+	Checked uint8       ` + "`after:\"Verify\"`" + `
+	_       struct{}    ` + "`len:\"Size\" demux:\"Route(),uint16\"`" + `
+	Routed  []byte      ` + "`demux:\"-\"`" + `
+}
+
+type Optional struct {
+	Next *Optional
+	Note uint8 ` + "`bingo:\"optional\"`" + `
+}
+
+type Untagged struct {
+	Next *Untagged
+	Done chan bool
+}
+`
+
+func TestCheck(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "records.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+	}
+	if _, err := new(types.Config).Check("records", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, d := range Check([]*ast.File{file}, info) {
+		if off := fset.Position(d.Pos).Offset; off < strings.Index(source, "type Broken") || off > strings.Index(source, "type Untagged") {
+			t.Errorf("Diagnostic outside of Broken at %v: %v", fset.Position(d.Pos), d.Message)
+		}
+		messages = append(messages, d.Message)
+	}
+	expected := []string{
+		"Field 'Count' not found. Referenced from a `len` tag on 'Data'.",
+		"Can't have both `len` and `size` tags on 'Both'.",
+		"Field 'Name' referenced from a `size` tag on 'Text' is a string. Expected an integer.",
+		"Method 'Length()' not found. Referenced from a `len` tag on 'Extra'.",
+		"Can't parse 'Next *records.Broken'. Pointer fields are not supported.",
+		"Can't parse 'Values map[int]int'. Map fields require a `len` tag.",
+		"Can't parse 'Flag bool'. Bool fields require a `bits` tag.",
+		"Method 'Verify()' not found. Referenced from an `after` tag on 'Checked'.",
+		"Method 'Route()' not found. Referenced from a `demux` tag on '_'.",
+		"Can't parse 'Next *records.Optional'. Pointer fields are not supported.",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Incorrect diagnostics:\n%v", strings.Join(messages, "\n"))
+	}
+}
+
+func TestTablesUpToDate(t *testing.T) {
+	src, err := os.ReadFile("../tagtables.go")
+	if err != nil {
+		t.Skip("The bingo package isn't there:", err)
+	}
+	generated, err := os.ReadFile("tables.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(generated), strings.Replace(string(src), "package bingo", "package tagcheck", 1)) {
+		t.Error("tables.go is out of date. Run go generate.")
+	}
+}
@@ -0,0 +1,75 @@
+package bingo
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var bingoTagCache sync.Map // map[reflect.StructTag]map[string]string
+
+// Returns the value of the given tag of a field. Besides the separate tags,
+// like `len:"Count" pad:"4"`, all options can be put in a single `bingo` tag
+// to avoid collisions with the tags of other packages:
+//
+//	Data []byte `bingo:"len=Count,pad=4,if=HasData"`
+//
+// Values may contain commas themselves, as in `bingo:"case=2,3,pad=4"`; a
+// comma only starts a new option when it's followed by a name and '='. A
+// separate tag takes precedence over the same option in the `bingo` tag.
+func getTag(fieldtyp reflect.StructField, key string) string {
+	if value, ok := fieldtyp.Tag.Lookup(key); ok {
+		return value
+	}
+	combined, ok := fieldtyp.Tag.Lookup("bingo")
+	if !ok {
+		return ""
+	}
+
+	opts, ok := bingoTagCache.Load(fieldtyp.Tag)
+	if !ok {
+		opts, _ = bingoTagCache.LoadOrStore(fieldtyp.Tag, parseBingoTag(combined))
+	}
+	return opts.(map[string]string)[key]
+}
+
+func parseBingoTag(combined string) map[string]string {
+	opts := make(map[string]string)
+	var key string
+	for _, part := range strings.Split(combined, ",") {
+		// Options of the option before, as in "offset=Off,base=struct", are
+		// part of its value
+		if name, value, ok := strings.Cut(part, "="); ok && isOptionName(name) && !slices.Contains(tagOptions[key], name) {
+			key = name
+			opts[key] = value
+		} else if flagTags[part] {
+			// flags without a value, like "optional", are turned on
+			key = ""
+			opts[part] = "true"
+		} else if len(key) > 0 {
+			opts[key] += "," + part
+		} else {
+			// options without a value, like "-"
+			opts[part] = ""
+		}
+	}
+	return opts
+}
+
+func isOptionName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether a field is excluded from parsing with `bingo:"-"`.
+func isIgnored(fieldtyp reflect.StructField) bool {
+	return fieldtyp.Tag.Get("bingo") == "-"
+}
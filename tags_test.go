@@ -0,0 +1,81 @@
+package bingo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBingoTag(t *testing.T) {
+	data := []byte{2, 1, 'h', 'i', 0, 0, 0x34, 0x12}
+	s := struct {
+		Count   uint8
+		HasData uint8
+		Data    []byte `bingo:"len=Count,pad=4,if=HasData && Count>0"`
+		Kind    uint16 `bingo:"magic=0x1234" json:"kind"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if string(s.Data) != "hi" {
+		t.Error("Error parsing field with bingo tag:", s.Data)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestParseBingoTag(t *testing.T) {
+	opts := parseBingoTag("case=2,3,unit=ms,uint16,if=A==1")
+	if len(opts) != 3 || opts["case"] != "2,3" || opts["unit"] != "ms,uint16" || opts["if"] != "A==1" {
+		t.Error("Error parsing bingo tag:", opts)
+	}
+}
+
+func TestBingoTagOffsetBase(t *testing.T) {
+	opts := parseBingoTag("len=1,offset=Off,base=struct,optional")
+	if len(opts) != 3 || opts["len"] != "1" || opts["offset"] != "Off,base=struct" || opts["optional"] != "true" {
+		t.Error("Error parsing bingo tag:", opts)
+	}
+
+	data := []byte{0x11, 0xFF, 2, 0x22}
+	for _, opts := range []ParseOptions{Default, Strict} {
+		s := struct {
+			Header uint8
+			Record struct {
+				Kind  uint8
+				Off   uint8
+				Value []byte `bingo:"len=1,offset=Off,base=struct"`
+			}
+		}{}
+		if err := NewParser(bytes.NewReader(data), LittleEndian, opts).EmitReadStruct(&s); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Record.Value) != 1 || s.Record.Value[0] != 0x22 {
+			t.Errorf("Incorrect value with options %v: %v", opts, s.Record.Value)
+		}
+	}
+}
+
+func TestBingoIgnore(t *testing.T) {
+	data := []byte{1, 2}
+	s := struct {
+		A     uint8
+		Total int `bingo:"-"`
+		B     uint8
+	}{Total: 42}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.A != 1 || s.B != 2 || s.Total != 42 {
+		t.Error("Error skipping ignored field:", s)
+	}
+	if p.offset != 2 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
@@ -0,0 +1,33 @@
+package bingo
+
+// The tables of tags in this file are shared with the tagcheck package, whose
+// tables.go is generated from it with go generate. Keep it free of anything
+// but the tables.
+
+// Tags read by the parser, either on their own or inside a `bingo` tag.
+var knownTags = map[string]bool{
+	"after": true, "align": true, "assert": true, "before": true, "bit": true,
+	"bits": true, "byteorder": true, "case": true, "compute": true,
+	"default": true, "demux": true, "discard": true, "elemsize": true,
+	"factory": true, "flags": true, "if": true, "len": true, "lenadjust": true,
+	"lenprefix": true, "magic": true, "max": true, "min": true, "offset": true,
+	"offsetof": true, "optional": true, "pad": true, "padcheck": true,
+	"parser": true, "size": true, "sizeref": true, "skip": true, "switch": true,
+	"terminator": true, "time": true, "transform": true, "type": true,
+	"unit": true, "until": true, "uuid": true, "valid": true, "varint": true,
+	"warn": true,
+}
+
+// Options that tags take after their value, as in `offset:"Off,base=struct"`
+var tagOptions = map[string][]string{"offset": {"base"}}
+
+// Tags that turn an option of a field on or off, as in `optional:"true"`
+var flagTags = map[string]bool{"discard": true, "optional": true}
+
+// Tags referring to an integer field or to a method that returns one
+var refTags = []string{"len", "size", "elemsize", "skip", "switch", "type", "offset"}
+
+// Tags naming a method of the struct, which has to exist. A `demux` tag may
+// follow the method with the type of the codes, and is "-" on the fields
+// records are routed to.
+var methodTags = []string{"after", "before", "compute", "demux", "factory", "parser", "transform", "until"}
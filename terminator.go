@@ -0,0 +1,158 @@
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+)
+
+func (p *Parser) parseTerminator(termkey string) ([]byte, error) {
+	term, ok := parseBytesLiteral(termkey)
+	if !ok || len(term) == 0 {
+		return nil, p.errorf(ErrInvalidTag, nil, "Invalid value for `terminator` tag: %v. Expected a hex or quoted string.", termkey)
+	}
+	return term, nil
+}
+
+// Reads bytes up to and including the terminator, returning them without it.
+func (p *Parser) readUntil(term []byte) ([]byte, error) {
+	last := term[len(term)-1]
+	var buf []byte
+	for {
+		chunk, err := p.readThrough(last)
+		buf = append(buf, chunk...)
+		p.offset += uint64(len(chunk))
+		if err != nil {
+			return nil, p.shortReadError(err, 1, 0)
+		}
+		if bytes.HasSuffix(buf, term) {
+			return buf[:len(buf)-len(term)], nil
+		}
+	}
+}
+
+// Reads bytes up to and including the next delim. The bytes read before an
+// error are returned with it. Buffered readers are scanned for delim a buffer
+// at a time rather than read one byte at a time.
+func (p *Parser) readThrough(delim byte) ([]byte, error) {
+	switch r := p.r.(type) {
+	case interface{ ReadSlice(byte) ([]byte, error) }:
+		var buf []byte
+		for {
+			chunk, err := r.ReadSlice(delim)
+			buf = append(buf, chunk...)
+			if err != bufio.ErrBufferFull {
+				return buf, err
+			}
+		}
+	case io.ByteReader:
+		var buf []byte
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return buf, err
+			}
+			buf = append(buf, b)
+			if b == delim {
+				return buf, nil
+			}
+		}
+	}
+	var buf []byte
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(p.r, b[:]); err != nil {
+			return buf, err
+		}
+		buf = append(buf, b[0])
+		if b[0] == delim {
+			return buf, nil
+		}
+	}
+}
+
+// Reads a slice that ends with the sequence given by its `terminator` tag,
+// as in `terminator:"0x00"`. The terminator is consumed but not stored. For
+// slices of other fixed-size types than bytes, the terminator must be exactly
+// one element long and is compared with each element's raw bytes.
+func (p *Parser) readTerminatedSlice(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, termkey string) error {
+	term, err := p.parseTerminator(termkey)
+	if err != nil {
+		return err
+	}
+	slicetyp := fieldval.Type()
+
+	if slicetyp.Elem().Kind() == reflect.Uint8 {
+		buf, err := p.readUntil(term)
+		if err != nil {
+			return err
+		}
+		fieldval.Set(reflect.ValueOf(p.internBytes(buf)).Convert(slicetyp))
+		return nil
+	}
+
+	elemsize := binary.Size(reflect.Zero(slicetyp.Elem()).Interface())
+	if elemsize <= 0 {
+		return p.errorf(ErrUnsupportedType, nil, "Error parsing field '%v %v'. The `terminator` tag requires fixed-size elements.", fieldtyp.Name, fieldtyp.Type)
+	}
+	if elemsize != len(term) {
+		return p.errorf(nil, nil, "Error parsing field '%v %v'. Terminator %v doesn't match the element size of %v bytes.", fieldtyp.Name, fieldtyp.Type, termkey, elemsize)
+	}
+
+	slice := reflect.MakeSlice(slicetyp, 0, 0)
+	buf := make([]byte, elemsize)
+	for {
+		if err := p.EmitReadFull(buf); err != nil {
+			return err
+		}
+		if bytes.Equal(buf, term) {
+			break
+		}
+		elem := reflect.New(slicetyp.Elem())
+		if err := binary.Read(bytes.NewReader(buf), p.byteOrder, elem.Interface()); err != nil {
+			return p.wrapError(err)
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	fieldval.Set(slice)
+	return nil
+}
+
+// Reads elements into a slice until the method named by its `until` tag
+// returns true. The method is called on the containing struct after each
+// element with the *Parser and the element's index; the slice field already
+// holds that element at that point:
+//
+//	func (d *Document) IsLastBlock(p *bingo.Parser, index int) bool {
+//		return d.Blocks[index].Kind == 0
+//	}
+func (p *Parser) readSliceUntil(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value, untilkey string) error {
+	meth, ok := methodByName(ptrval.Type(), untilkey)
+	if !ok {
+		return p.errorf(ErrTagReference, nil, "Method '%v' for '%v' not found. Referenced from an `until` tag.", untilkey, ptrval.Type())
+	}
+	if mt := meth.Type; mt.NumIn() != 3 || mt.In(1) != parserType || mt.In(2).Kind() != reflect.Int || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+		return p.errorf(ErrTagReference, nil, "Invalid signature of method '%v' on '%v'. Referenced from an `until` tag in '%v %v'. Expected func(*bingo.Parser, int) bool.", untilkey, ptrval.Type(), fieldtyp.Name, fieldtyp.Type)
+	}
+	ctxval := reflect.ValueOf(p)
+
+	slice := reflect.MakeSlice(fieldval.Type(), 0, 0)
+	for i := 0; ; i++ {
+		elem := reflect.New(fieldval.Type().Elem()).Elem()
+		if err := p.readElem(elem, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+		fieldval.Set(slice)
+
+		results, err := p.callMethod(meth, []reflect.Value{ptrval, ctxval, reflect.ValueOf(i)})
+		if err != nil {
+			return err
+		}
+		if results[0].Bool() {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTerminatorTag(t *testing.T) {
+	data := []byte{'a', 'b', 'c', 0,
+		'd', '\r', 'e', '\r', '\n',
+		1, 0, 2, 0, 0, 0,
+		0xEE}
+	s := struct {
+		Name    string   `terminator:"0x00"`
+		Line    []byte   `terminator:"'\r\n'"`
+		Values  []uint16 `terminator:"0x0000"`
+		Trailer uint8
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Name != "abc" {
+		t.Error("Error parsing null-terminated string:", s.Name)
+	}
+	if string(s.Line) != "d\re" {
+		t.Error("Error parsing slice with multi-byte terminator:", s.Line)
+	}
+	if !isEqualu16(s.Values, []uint16{1, 2}) {
+		t.Error("Error parsing terminated []uint16:", s.Values)
+	}
+	if s.Trailer != 0xEE {
+		t.Error("Error parsing field after terminated slices:", s.Trailer)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestTerminatorElemSizeMismatch(t *testing.T) {
+	s := struct {
+		Values []uint16 `terminator:"0x00"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error parsing field 'Values []uint16'. Terminator 0x00 doesn't match the element size of 2 bytes." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestTerminatorMissing(t *testing.T) {
+	s := struct {
+		Name string `terminator:"0x00"`
+	}{}
+	p := newParserData([]byte("abc"))
+
+	if err := p.EmitReadStruct(&s); err == nil {
+		t.Error("Expected an error for a missing terminator")
+	}
+	if p.offset != 3 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestTerminatorBuffered(t *testing.T) {
+	// The line is longer than the buffer and its terminator straddles two
+	// fills of it
+	line := bytes.Repeat([]byte{'x'}, 31)
+	data := append(append(line, '\r', '\n'), 0xEE)
+	s := struct {
+		Line    []byte `terminator:"'\r\n'"`
+		Trailer uint8
+	}{}
+	p := NewParser(&readCounter{ReadSeeker: bytes.NewReader(data)}, LittleEndian, Default)
+	p.SetBufferSize(16)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.Line, line) || s.Trailer != 0xEE {
+		t.Errorf("Incorrect struct: %q %v", s.Line, s.Trailer)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+type ResourceBlock struct {
+	Kind uint8
+	Data [2]byte
+}
+
+type ResourceSection struct {
+	Blocks  []ResourceBlock `until:"IsLastBlock"`
+	Trailer uint8
+}
+
+func (r *ResourceSection) IsLastBlock(p *Parser, index int) bool {
+	return r.Blocks[index].Kind == 0
+}
+
+func TestUntilTag(t *testing.T) {
+	data := []byte{1, 'a', 'b',
+		2, 'c', 'd',
+		0, 0, 0,
+		0xEE}
+	s := ResourceSection{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Blocks) != 3 {
+		t.Fatal("Invalid number of blocks:", len(s.Blocks))
+	}
+	if !(s.Blocks[0].Kind == 1 && string(s.Blocks[1].Data[:]) == "cd" && s.Blocks[2].Kind == 0) {
+		t.Error("Error parsing blocks:", s.Blocks)
+	}
+	if s.Trailer != 0xEE {
+		t.Error("Error parsing field after blocks:", s.Trailer)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestUntilTagMissingMethod(t *testing.T) {
+	s := struct {
+		Blocks []ResourceBlock `until:"IsLast"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Method 'IsLast' for '*struct { Blocks []bingo.ResourceBlock \"until:\\\"IsLast\\\"\" }' not found. Referenced from an `until` tag." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+type voidUntil struct {
+	Blocks []ResourceBlock `until:"Done"`
+}
+
+func (v *voidUntil) Done(p *Parser, index int) {}
+
+func TestUntilTagWrongSignature(t *testing.T) {
+	var s voidUntil
+	err := newParserData([]byte{0, 0, 0}).EmitReadStruct(&s)
+	if !errors.Is(err, ErrTagReference) || err.Error() != "Invalid signature of method 'Done' on '*bingo.voidUntil'. Referenced from an `until` tag in 'Blocks []bingo.ResourceBlock'. Expected func(*bingo.Parser, int) bool." {
+		t.Error("Incorrect error:", err)
+	}
+}
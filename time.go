@@ -0,0 +1,119 @@
+package bingo
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Number of seconds between the Windows FILETIME epoch (1601-01-01) and the
+// Unix epoch
+const filetimeEpochDelta = 11644473600
+
+// Reads a timestamp into a time.Time field. The on-disk representation is
+// selected with the `time` tag.
+func (p *Parser) readTime(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	var t time.Time
+
+	switch format := getTag(fieldtyp, "time"); format {
+	case "unix32":
+		// seconds since the Unix epoch
+		var secs uint32
+		if err := p.EmitReadFixedFast(&secs, 4, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		t = time.Unix(int64(secs), 0)
+
+	case "unix64ms":
+		// milliseconds since the Unix epoch
+		var msecs int64
+		if err := p.EmitReadFixedFast(&msecs, 8, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		t = time.UnixMilli(msecs)
+
+	case "filetime":
+		// 100-nanosecond intervals since 1601-01-01
+		var ticks uint64
+		if err := p.EmitReadFixedFast(&ticks, 8, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		secs := int64(ticks/1e7) - filetimeEpochDelta
+		t = time.Unix(secs, int64(ticks%1e7)*100)
+
+	case "dosdatetime":
+		// MS-DOS time followed by MS-DOS date, as stored in ZIP headers
+		var dostime, dosdate uint16
+		if err := p.EmitReadFixedFast(&dostime, 2, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		if err := p.EmitReadFixedFast(&dosdate, 2, fieldtyp, ptrval); err != nil {
+			return err
+		}
+		t = time.Date(
+			int(dosdate>>9)+1980, time.Month(dosdate>>5&0xF), int(dosdate&0x1F),
+			int(dostime>>11), int(dostime>>5&0x3F), int(dostime&0x1F)*2,
+			0, time.UTC)
+
+	case "":
+		return p.errorf(ErrInvalidTag, nil, "Error reading field '%v %v'. Missing `time` tag.", fieldtyp.Name, fieldtyp.Type)
+
+	default:
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `time` tag: %v. Expected one of unix32, unix64ms, filetime, dosdatetime.", format)
+	}
+
+	fieldval.Set(reflect.ValueOf(t.UTC()))
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// Reads an integer into a time.Duration field, scaling it by the unit given in
+// the `unit` tag. The tag may also name the stored integer type, as in
+// `unit:"ms,uint16"`; int64 is assumed otherwise. Without the tag the field is
+// read as int64 nanoseconds like any other int64.
+func (p *Parser) readDuration(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	unitstr := getTag(fieldtyp, "unit")
+	if len(unitstr) == 0 {
+		unitstr = "ns"
+	}
+
+	storage := "int64"
+	if idx := strings.IndexByte(unitstr, ','); idx >= 0 {
+		unitstr, storage = unitstr[:idx], unitstr[idx+1:]
+	}
+	unit, ok := durationUnits[unitstr]
+	if !ok {
+		return p.errorf(ErrInvalidTag, nil, "Invalid unit in `unit` tag: %v. Expected one of ns, us, ms, s, m, h.", unitstr)
+	}
+
+	raw, err := p.readStorage("unit", storage, fieldtyp, ptrval)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if raw.CanInt() {
+		count = raw.Int()
+	} else if raw.Uint() > math.MaxInt64 {
+		return p.errorf(nil, nil, "Error reading field '%v %v'. Value %v%v overflows it.", fieldtyp.Name, fieldtyp.Type, raw.Uint(), unitstr)
+	} else {
+		count = int64(raw.Uint())
+	}
+	if count > math.MaxInt64/int64(unit) || count < math.MinInt64/int64(unit) {
+		return p.errorf(nil, nil, "Error reading field '%v %v'. Value %v%v overflows it.", fieldtyp.Name, fieldtyp.Type, count, unitstr)
+	}
+	fieldval.SetInt(int64(time.Duration(count) * unit))
+	return nil
+}
@@ -0,0 +1,137 @@
+package bingo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeFields(t *testing.T) {
+	data := []byte{0, 202, 154, 59, // unix32
+		123, 152, 247, 62, 93, 1, 0, 0, // unix64ms
+		5, 128, 255, 68, 209, 56, 193, 1, // filetime
+		175, 109, 207, 80} // dosdatetime
+	s := struct {
+		Created  time.Time `time:"unix32"`
+		Modified time.Time `time:"unix64ms"`
+		Accessed time.Time `time:"filetime"`
+		Stamp    time.Time `time:"dosdatetime"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if !s.Created.Equal(time.Date(2001, 9, 9, 1, 46, 40, 0, time.UTC)) {
+		t.Error("Error parsing unix32 time:", s.Created)
+	}
+	if !s.Modified.Equal(time.Date(2017, 7, 14, 2, 40, 0, 123e6, time.UTC)) {
+		t.Error("Error parsing unix64ms time:", s.Modified)
+	}
+	if !s.Accessed.Equal(time.Date(2001, 9, 9, 1, 46, 40, 500, time.UTC)) {
+		t.Error("Error parsing filetime time:", s.Accessed)
+	}
+	if !s.Stamp.Equal(time.Date(2020, 6, 15, 13, 45, 30, 0, time.UTC)) {
+		t.Error("Error parsing dosdatetime time:", s.Stamp)
+	}
+	if p.offset != 24 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestTimeMissingTag(t *testing.T) {
+	s := struct {
+		Created time.Time
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Created time.Time'. Missing `time` tag." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 0 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestTimeInvalidTag(t *testing.T) {
+	s := struct {
+		Created time.Time `time:"unix16"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid value for `time` tag: unix16. Expected one of unix32, unix64ms, filetime, dosdatetime." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestDurationFields(t *testing.T) {
+	data := []byte{0xE8, 0x03, // 1000ms
+		0x02, 0, 0, 0, 0, 0, 0, 0, // 2h
+		0xFF} // -1s
+	s := struct {
+		Timeout  time.Duration `unit:"ms,uint16"`
+		Lifetime time.Duration `unit:"h"`
+		Delay    time.Duration `unit:"s,int8"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Timeout != time.Second {
+		t.Error("Error parsing duration in ms:", s.Timeout)
+	}
+	if s.Lifetime != 2*time.Hour {
+		t.Error("Error parsing duration in h:", s.Lifetime)
+	}
+	if s.Delay != -time.Second {
+		t.Error("Error parsing signed duration:", s.Delay)
+	}
+	if p.offset != 11 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestDurationInvalidUnit(t *testing.T) {
+	s := struct {
+		Timeout time.Duration `unit:"days"`
+	}{}
+	p := newParser()
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Invalid unit in `unit` tag: days. Expected one of ns, us, ms, s, m, h." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+
+	if p.offset != 0 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestDurationOverflow(t *testing.T) {
+	s := struct {
+		Lifetime time.Duration `unit:"h,uint32"`
+	}{}
+	p := newParserData([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading field 'Lifetime time.Duration'. Value 4294967295h overflows it." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error("Expected an error. Got", s.Lifetime)
+	}
+}
@@ -0,0 +1,59 @@
+package bingo
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Byte arrays and slices longer than this are cut short in the trace
+const traceMaxBytes = 16
+
+// SetTrace makes the parser write a line to w for every field it reads:
+//
+//	offset 0x1A: SlicesHeader.Count uint32 = 2
+//
+// The offset is where the field starts and the path is rooted at the type of
+// the struct passed to EmitReadStruct. A field holding a struct gets its line
+// before the lines for its own fields, without a value. Pass nil to turn the
+// trace off.
+func (p *Parser) SetTrace(w io.Writer) {
+	p.trace = w
+}
+
+// Reports whether a field's line is written before its contents are read
+// rather than after.
+func traceBefore(fieldval reflect.Value) bool {
+	return fieldval.Kind() == reflect.Struct && fieldval.Type() != timeType
+}
+
+// Writes the trace line for the field the parser is at, which started at
+// offset. The value is left out if withValue is false.
+func (p *Parser) traceField(offset uint64, fieldval reflect.Value, withValue bool) {
+	path := p.rootedFieldPath()
+	top := p.stack[len(p.stack)-1]
+	if !withValue {
+		fmt.Fprintf(p.trace, "offset 0x%X: %v %v\n", offset, path, top.fieldType)
+		return
+	}
+	fmt.Fprintf(p.trace, "offset 0x%X: %v %v = %v\n", offset, path, top.fieldType, formatTraceValue(fieldval))
+}
+
+func formatTraceValue(val reflect.Value) string {
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice:
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		b := make([]byte, val.Len())
+		reflect.Copy(reflect.ValueOf(b), val)
+		if len(b) > traceMaxBytes {
+			return formatBytes(b[:traceMaxBytes]) + " ..."
+		}
+		return formatBytes(b)
+	}
+	if val.CanInterface() {
+		return fmt.Sprint(val.Interface())
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,63 @@
+package bingo
+
+import (
+	"strings"
+	"testing"
+)
+
+type traceEntry struct {
+	ID   uint16
+	Name [4]byte
+}
+
+type TraceHeader struct {
+	Magic   [2]byte `magic:"'BG'"`
+	Count   uint8
+	Entries []traceEntry `len:"Count"`
+	Data    []byte       `len:"20"`
+}
+
+func TestTrace(t *testing.T) {
+	s := TraceHeader{}
+	data := append([]byte{'B', 'G', 2, 1, 0, 'a', 'b', 'c', 'd', 2, 0, 'e', 'f', 'g', 'h'}, make([]byte, 20)...)
+	p := newParserData(data)
+	var b strings.Builder
+	p.SetTrace(&b)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	expected := `offset 0x0: TraceHeader.Magic [2]uint8 = 42 47
+offset 0x2: TraceHeader.Count uint8 = 2
+offset 0x3: TraceHeader.Entries []bingo.traceEntry = [{1 [97 98 99 100]} {2 [101 102 103 104]}]
+offset 0xF: TraceHeader.Data []uint8 = 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 ...
+`
+	if b.String() != expected {
+		t.Errorf("Incorrect trace:\n%v", b.String())
+	}
+}
+
+func TestTraceNested(t *testing.T) {
+	s := struct {
+		Header struct {
+			Version uint8
+			Flags   uint8
+		}
+		Entries []traceEntry `len:"1"`
+	}{}
+	p := newParserData([]byte{1, 2, 3, 4, 0, 0, 0, 0, 0, 0})
+	var b strings.Builder
+	p.SetTrace(&b)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	expected := `offset 0x0: Header struct { Version uint8; Flags uint8 }
+offset 0x0: Header.Version uint8 = 1
+offset 0x1: Header.Flags uint8 = 2
+offset 0x2: Entries []bingo.traceEntry = [{1027 [0 0 0 0]}]
+`
+	if b.String() != expected {
+		t.Errorf("Incorrect trace:\n%v", b.String())
+	}
+}
@@ -0,0 +1,96 @@
+package bingo
+
+import (
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"reflect"
+	"unsafe"
+)
+
+var (
+	byteOrdererType  = reflect.TypeOf((*ByteOrderer)(nil)).Elem()
+	beforeParserType = reflect.TypeOf((*BeforeParser)(nil)).Elem()
+	afterParserType  = reflect.TypeOf((*AfterParser)(nil)).Elem()
+)
+
+// Reports whether structs of type typ, with the given plan, hold nothing but
+// plain fixed-size fields laid out in memory exactly as in the stream, and
+// have no hooks to call.
+func isRawStruct(typ reflect.Type, plan *structPlan) bool {
+	if len(plan.fields) == 0 || plan.fields[0].run != len(plan.fields) || uintptr(plan.fields[0].runSize) != typ.Size() {
+		return false
+	}
+	ptrtyp := reflect.PtrTo(typ)
+	return !ptrtyp.Implements(byteOrdererType) && !ptrtyp.Implements(beforeParserType) && !ptrtyp.Implements(afterParserType)
+}
+
+// Bytes decoded to tell byte orders apart, kept here as they'd escape to the
+// heap through the ByteOrder interface otherwise
+var orderProbe = []byte{1, 2}
+
+// Reports whether values in the parser's byte order can be copied into
+// memory as they are.
+func (p *Parser) nativeOrder() bool {
+	return p.byteOrder.Uint16(orderProbe) == binary.NativeEndian.Uint16(orderProbe)
+}
+
+// With the Unsafe option, structs made of nothing but untagged fixed-size
+// numbers and arrays of them, with no gaps between the fields and no hooks,
+// are copied from the stream straight into their memory when the parser's
+// byte order is the machine's, and so are slices of them. Tracing, logging
+// fields or recording a field map turns this off, as these look at each
+// field.
+//
+// Reports whether a struct of the given plan can be read that way.
+func (p *Parser) canReadRaw(plan *structPlan) bool {
+	return p.unsafe && plan.raw && p.nativeOrder() && p.trace == nil && !p.recordFields && !p.slogEnabled(slog.LevelDebug)
+}
+
+// Reads a struct that can be read raw, as the top of the stack, straight
+// into its memory.
+func (p *Parser) readRawStruct(ptrval reflect.Value, plan *structPlan) error {
+	p.alignBits()
+	top := len(p.stack) - 1
+	buf := unsafe.Slice((*byte)(ptrval.UnsafePointer()), ptrval.Elem().Type().Size())
+	got, err := io.ReadFull(p.r, buf)
+	if err != nil {
+		// Locate the field that was cut short
+		for _, f := range plan.fields {
+			if got < f.size {
+				p.stack[top].field, p.stack[top].fieldType = f.Name, f.Type
+				p.offset += uint64(f.Offset)
+				if got == 0 {
+					err = io.EOF
+				} else if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return p.shortReadError(err, int64(f.size), int64(got))
+			}
+			got -= f.size
+		}
+		return p.wrapError(err)
+	}
+	last := plan.fields[len(plan.fields)-1]
+	p.stack[top].field, p.stack[top].fieldType = last.Name, last.Type
+	p.offset += uint64(len(buf))
+	p.lastStack, p.lastOffset = append(p.lastStack[:0], p.stack...), p.offset
+	return nil
+}
+
+// Reads a slice of structs that can be read raw straight into the memory of
+// the slice.
+func (p *Parser) readRawSlice(slice reflect.Value) error {
+	p.alignBits()
+	size := slice.Len() * int(slice.Type().Elem().Size())
+	if size == 0 {
+		return nil
+	}
+	buf := unsafe.Slice((*byte)(slice.UnsafePointer()), size)
+	got, err := io.ReadFull(p.r, buf)
+	if err != nil {
+		return p.shortReadError(err, int64(size), int64(got))
+	}
+	p.offset += uint64(size)
+	return nil
+}
@@ -0,0 +1,88 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type rawPoint struct {
+	X, Y int16
+	Tag  [4]byte
+}
+
+type rawPadded struct {
+	A uint8
+	B uint32
+}
+
+func newUnsafeParser(data []byte) *Parser {
+	return NewParser(bytes.NewReader(data), LittleEndian, Unsafe)
+}
+
+func TestUnsafeStruct(t *testing.T) {
+	s := struct {
+		Count  uint8
+		Origin rawPoint
+		Points []rawPoint `len:"Count"`
+	}{}
+	data := []byte{2, 1, 0, 0xFF, 0xFF, 'o', 'r', 'i', 'g', 2, 0, 3, 0, 'a', 'b', 'c', 'd', 4, 0, 5, 0, 'e', 'f', 'g', 'h'}
+	p := newUnsafeParser(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Origin != (rawPoint{1, -1, [4]byte{'o', 'r', 'i', 'g'}}) {
+		t.Errorf("Incorrect origin: %+v", s.Origin)
+	}
+	if len(s.Points) != 2 || s.Points[0] != (rawPoint{2, 3, [4]byte{'a', 'b', 'c', 'd'}}) || s.Points[1] != (rawPoint{4, 5, [4]byte{'e', 'f', 'g', 'h'}}) {
+		t.Errorf("Incorrect points: %+v", s.Points)
+	}
+	if p.Offset() != uint64(len(data)) {
+		t.Errorf("Invalid offset: %v", p.Offset())
+	}
+}
+
+func TestUnsafeTruncated(t *testing.T) {
+	s := rawPoint{}
+	p := newUnsafeParser([]byte{1, 0, 2})
+
+	err := p.EmitReadStruct(&s)
+	if !errors.Is(err, ErrUnexpectedEOF) || err.Error() != "Unexpected end of data while reading 'Y int16' of bingo.rawPoint at offset 2: expected 2 bytes, got 1." {
+		t.Error("Incorrect error:", err)
+	}
+}
+
+func TestUnsafeOtherOrder(t *testing.T) {
+	s := rawPoint{}
+	p := NewParser(bytes.NewReader([]byte{0, 1, 0, 2, 'a', 'b', 'c', 'd'}), BigEndian, Unsafe)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != (rawPoint{1, 2, [4]byte{'a', 'b', 'c', 'd'}}) {
+		t.Errorf("Incorrect struct: %+v", s)
+	}
+}
+
+func TestRawStruct(t *testing.T) {
+	tests := []struct {
+		typ reflect.Type
+		raw bool
+	}{
+		{reflect.TypeOf(rawPoint{}), true},
+		{reflect.TypeOf(rawPadded{}), false},
+		{reflect.TypeOf(traceEntry{}), true},
+		{reflect.TypeOf(TraceHeader{}), false},
+		{reflect.TypeOf(struct {
+			A uint16 `assert:"1"`
+		}{}), false},
+		{reflect.TypeOf(struct{}{}), false},
+	}
+	for _, test := range tests {
+		if raw := planFor(test.typ).raw; raw != test.raw {
+			t.Errorf("Expected raw to be %v for %v", test.raw, test.typ)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package bingo
+
+import (
+	"encoding/hex"
+	"reflect"
+)
+
+// UUID is a 16-byte universally unique identifier. Fields of this type are
+// read as 16 raw bytes in RFC 4122 order. Tagging a field with `uuid:"mixed"`
+// reads it in the Microsoft GUID layout instead, where the first three groups
+// are stored little-endian (as found in GPT headers and COM-based formats).
+type UUID [16]byte
+
+var uuidType = reflect.TypeOf(UUID{})
+
+// String returns the canonical textual form of the UUID, e.g.
+// "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (p *Parser) readUUID(fieldval reflect.Value, fieldtyp reflect.StructField, ptrval reflect.Value) error {
+	var u UUID
+	if err := p.EmitReadFixedFast(&u, len(u), fieldtyp, ptrval); err != nil {
+		return err
+	}
+
+	switch layout := getTag(fieldtyp, "uuid"); layout {
+	case "":
+	case "mixed":
+		u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+		u[4], u[5] = u[5], u[4]
+		u[6], u[7] = u[7], u[6]
+	default:
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `uuid` tag: %v. Expected \"mixed\".", layout)
+	}
+
+	fieldval.Set(reflect.ValueOf(u))
+	return nil
+}
@@ -0,0 +1,43 @@
+package bingo
+
+import (
+	"testing"
+)
+
+var uuidData = []byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+func TestUUIDField(t *testing.T) {
+	s := struct {
+		ID UUID
+	}{}
+	p := newParserData(uuidData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.ID.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Error("Error parsing UUID:", s.ID)
+	}
+	if p.offset != 16 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestUUIDFieldMixed(t *testing.T) {
+	s := struct {
+		ID UUID `uuid:"mixed"`
+	}{}
+	p := newParserData(uuidData)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.ID.String() != "10b8a76b-ad9d-d111-80b4-00c04fd430c8" {
+		t.Error("Error parsing mixed-endian UUID:", s.ID)
+	}
+	if p.offset != 16 {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
@@ -0,0 +1,198 @@
+package bingo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the tags of the struct type typ and of the struct types
+// nested in it, as Compile does, without making a Decoder. Calling it from a
+// test or an init function for each type a program parses makes mistakes in
+// the struct definitions surface there rather than in the middle of a parse,
+// possibly only for the rare input that takes the path they're on.
+func Validate(typ reflect.Type) error {
+	_, err := Compile(typ)
+	return err
+}
+
+// ValidateStruct is Validate for the struct type T.
+func ValidateStruct[T any]() error {
+	return Validate(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Number of arguments of the methods named by hook tags, the receiver and
+// the *Parser included
+var hookArgs = map[string]int{"before": 3, "compute": 2, "factory": 3, "parser": 3, "transform": 3}
+
+// Checks what can be checked of the tags of a field of the struct type typ
+// without any data: that they don't conflict with each other, that their
+// values are well formed and that the fields and methods they refer to exist
+// and are of the right kind. References to enclosing structs, with "^.", are
+// only resolved while parsing.
+func checkFieldTags(typ reflect.Type, plan *structPlan, f *fieldPlan) *ParseError {
+	problem := func(kind error, format string, args ...interface{}) *ParseError {
+		return &ParseError{FieldPath: f.Name, Type: f.Type, kind: kind, text: fmt.Sprintf(format, args...)}
+	}
+	has := func(tag string) bool {
+		return len(f.tags[tag]) > 0
+	}
+
+	if err := checkHookSignatures(typ, plan, f); err != nil {
+		return err
+	}
+	if has("parser") || has("demux") {
+		// custom parsers and demux markers use tags their own way
+		return nil
+	}
+
+	if f.Type.Kind() == reflect.Slice {
+		if has("len") && has("size") {
+			return problem(ErrInvalidTag, "Error parsing field '%v %v'. Can't have both `len` and `size` tags on the same field.", f.Name, f.Type)
+		}
+		if has("lenprefix") && (has("len") || has("size")) {
+			return problem(ErrInvalidTag, "Error parsing field '%v %v'. Can't combine `lenprefix` with `len` or `size` tags.", f.Name, f.Type)
+		}
+		if (has("terminator") || has("until")) && (has("len") || has("size") || has("lenprefix")) || has("terminator") && has("until") {
+			return problem(ErrInvalidTag, "Error parsing field '%v %v'. Can't combine `terminator` or `until` with other length tags.", f.Name, f.Type)
+		}
+	}
+
+	for tag := range flagTags {
+		if value, ok := f.tags[tag]; ok {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return problem(ErrInvalidTag, "Invalid value for `%v` tag: %v. Expected true or false.", tag, value)
+			}
+		}
+	}
+	if padstr := f.tags["pad"]; len(padstr) > 0 {
+		if padding, err := strconv.ParseUint(padstr, 0, 8); err != nil {
+			return problem(ErrInvalidTag, "Invalid value for `pad` tag: %v. Expected an integer.", padstr)
+		} else if padding == 0 {
+			return problem(ErrInvalidTag, "Invalid value for `pad` tag: %v. Expected a positive integer.", padstr)
+		}
+	}
+	if alignstr := f.tags["align"]; len(alignstr) > 0 {
+		if alignment, err := strconv.ParseUint(alignstr, 0, 16); err != nil || alignment == 0 {
+			return problem(ErrInvalidTag, "Invalid value for `align` tag: %v. Expected a positive integer.", alignstr)
+		}
+	}
+
+	for _, tag := range refTags {
+		ref := f.tags[tag]
+		if tag == "offset" {
+			if idx := strings.IndexByte(ref, ','); idx >= 0 {
+				if base := ref[idx+1:]; base != "base=file" && base != "base=struct" && base != "base=region" {
+					return problem(ErrInvalidTag, "Invalid option in `offset` tag: %v. Expected base=file, base=struct or base=region.", base)
+				}
+				ref = ref[:idx]
+			}
+		}
+		if len(ref) == 0 || ref == "<inf>" || ref == "<rest>" || strings.HasPrefix(ref, "^.") {
+			continue
+		}
+		if c := ref[0]; '0' <= c && c <= '9' {
+			if _, err := strconv.ParseUint(ref, 0, 64); err != nil {
+				return problem(ErrInvalidTag, "Invalid value for `%v` tag: %v. Expected an integer.", tag, ref)
+			}
+			continue
+		}
+		if name, ok := strings.CutSuffix(ref, "()"); ok {
+			meth, ok := plan.methods[name]
+			if !ok {
+				return problem(ErrTagReference, "Method '%v()' for '*%v' not found. Referenced from a `%v` tag.", name, typ, tag)
+			}
+			// elemsize methods are also passed the index of the element
+			nargs := 2
+			if tag == "elemsize" {
+				nargs = 3
+			}
+			if mt := meth.Type; mt.NumIn() != nargs || mt.In(1) != parserType || nargs == 3 && mt.In(2).Kind() != reflect.Int || mt.NumOut() == 0 || !isIntKind(mt.Out(0).Kind()) {
+				return problem(ErrTagReference, "Invalid signature of method '%v' on '*%v'. Referenced from a `%v` tag in '%v %v'. Expected a method returning an integer.", name, typ, tag, f.Name, f.Type)
+			}
+			continue
+		}
+		reftyp, ok := fieldTypeByPath(typ, ref)
+		if !ok {
+			return problem(ErrTagReference, "Field '%v' for '%v %v' not found. Referenced from a `%v` tag.", ref, f.Name, f.Type, tag)
+		}
+		if !isIntKind(reftyp.Kind()) {
+			return problem(ErrTagReference, "Field '%v' referenced from a `%v` tag in '%v %v' is a %v. Expected an integer.", ref, tag, f.Name, f.Type, reftyp)
+		}
+	}
+
+	if ifstr := f.tags["if"]; len(ifstr) > 0 {
+		if meth, ok := plan.methods[strings.TrimPrefix(ifstr, "!")]; ok {
+			if mt := meth.Type; mt.NumIn() != 2 || mt.In(1) != parserType || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+				return problem(ErrTagReference, "Invalid signature of method '%v' on '*%v'. Referenced from an `if` tag in '%v %v'. Expected func(*bingo.Parser) bool.", meth.Name, typ, f.Name, f.Type)
+			}
+		} else if _, err := compileExpr("if", ifstr); err != nil {
+			return problem(ErrInvalidTag, "Invalid expression in `if` tag: %v. %v.", ifstr, err)
+		}
+	}
+
+	if meth, ok := plan.methods[f.tags["until"]]; ok {
+		if mt := meth.Type; mt.NumIn() != 3 || mt.In(1) != parserType || mt.In(2).Kind() != reflect.Int || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+			return problem(ErrTagReference, "Invalid signature of method '%v' on '*%v'. Referenced from an `until` tag in '%v %v'. Expected func(*bingo.Parser, int) bool.", meth.Name, typ, f.Name, f.Type)
+		}
+	}
+
+	return nil
+}
+
+// Checks the number and types of the arguments of the methods named by the
+// hook tags of a field. Missing methods are reported by compile.
+func checkHookSignatures(typ reflect.Type, plan *structPlan, f *fieldPlan) *ParseError {
+	for _, tag := range methodTags {
+		nargs, ok := hookArgs[tag]
+		if !ok {
+			continue
+		}
+		meth, ok := plan.methods[strings.TrimSuffix(f.tags[tag], "()")]
+		if !ok {
+			continue
+		}
+		mt := meth.Type
+		valid := mt.NumIn() == nargs && mt.In(1) == parserType
+		switch tag {
+		case "parser", "transform":
+			valid = valid && mt.In(2) == reflect.PtrTo(f.Type)
+		case "before":
+			valid = valid && isIntKind(mt.In(2).Kind())
+		case "factory":
+			valid = valid && mt.In(2).Kind() == reflect.Int && mt.NumOut() == 1
+		}
+		if !valid {
+			return &ParseError{FieldPath: f.Name, Type: f.Type, kind: ErrTagReference, text: fmt.Sprintf("Invalid signature of method '%v' on '*%v'. Referenced from a `%v` tag in '%v %v'.", meth.Name, typ, tag, f.Name, f.Type)}
+		}
+	}
+	return nil
+}
+
+// Looks up the type of a field by a dot-separated path through nested
+// structs, as fieldByPath does for values.
+func fieldTypeByPath(typ reflect.Type, path string) (reflect.Type, bool) {
+	for more := true; more; {
+		var name string
+		name, path, more = strings.Cut(path, ".")
+		if typ.Kind() != reflect.Struct {
+			return nil, false
+		}
+		f, ok := typ.FieldByName(name)
+		if !ok {
+			return nil, false
+		}
+		typ = f.Type
+	}
+	return typ, true
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	for _, k := range intKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
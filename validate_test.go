@@ -0,0 +1,110 @@
+package bingo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validatedRecord struct {
+	Header struct {
+		Count uint16
+	}
+	Data  []byte   `len:"Header.Count" pad:"4"`
+	Size  uint8    `align:"2"`
+	Items []uint32 `size:"Size" elemsize:"ElemSize()"`
+	Extra uint8    `if:"Size > 4 && HasExtra"`
+	Name  string   `parser:"ParseName"`
+	Blobs []blob   `until:"IsLast"`
+}
+
+func (r *validatedRecord) ElemSize(p *Parser, index int) uint8 { return 4 }
+func (r *validatedRecord) HasExtra(p *Parser) bool             { return true }
+func (r *validatedRecord) ParseName(p *Parser, name *string) error {
+	return nil
+}
+func (r *validatedRecord) IsLast(p *Parser, index int) bool { return true }
+
+type wrongHooks struct {
+	Name string `parser:"ParseName"`
+}
+
+func (w *wrongHooks) ParseName(p *Parser, name *[]byte) error { return nil }
+
+type wrongRefMethod struct {
+	Data []byte `len:"Length()"`
+}
+
+func (w *wrongRefMethod) Length(p *Parser) string { return "" }
+
+func TestValidate(t *testing.T) {
+	if err := ValidateStruct[validatedRecord](); err != nil {
+		t.Error(err)
+	}
+	if err := Validate(reflect.TypeOf(sizedRecord{})); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		typ     reflect.Type
+		kind    error
+		message string
+	}{
+		{reflect.TypeOf(struct {
+			Data []byte `len:"1" size:"1"`
+		}{}), ErrInvalidTag, "Can't have both `len` and `size` tags"},
+		{reflect.TypeOf(struct {
+			Data []byte `lenprefix:"uint8" terminator:"0x00"`
+		}{}), ErrInvalidTag, "Can't combine `terminator` or `until`"},
+		{reflect.TypeOf(struct {
+			Data []byte `len:"1" pad:"0"`
+		}{}), ErrInvalidTag, "Invalid value for `pad` tag: 0. Expected a positive integer."},
+		{reflect.TypeOf(struct {
+			Data []byte `len:"1" pad:"four"`
+		}{}), ErrInvalidTag, "Invalid value for `pad` tag: four. Expected an integer."},
+		{reflect.TypeOf(struct {
+			A uint8 `align:"-2"`
+		}{}), ErrInvalidTag, "Invalid value for `align` tag: -2."},
+		{reflect.TypeOf(struct {
+			A uint8 `optional:"maybe"`
+		}{}), ErrInvalidTag, "Invalid value for `optional` tag: maybe. Expected true or false."},
+		{reflect.TypeOf(struct {
+			Data []byte `len:"Count"`
+		}{}), ErrTagReference, "Field 'Count' for 'Data []uint8' not found. Referenced from a `len` tag."},
+		{reflect.TypeOf(struct {
+			Inner struct{ Count uint8 }
+			Data  []byte `len:"Inner.Total"`
+		}{}), ErrTagReference, "Field 'Inner.Total' for 'Data []uint8' not found."},
+		{reflect.TypeOf(struct {
+			Name string
+			Data []byte `size:"Name"`
+		}{}), ErrTagReference, "Field 'Name' referenced from a `size` tag in 'Data []uint8' is a string."},
+		{reflect.TypeOf(struct {
+			Kind uint8
+			_    struct{} `switch:"Kind()"`
+		}{}), ErrTagReference, "Method 'Kind()' for"},
+		{reflect.TypeOf(struct {
+			Offset uint32
+			Data   []byte `offset:"Offset,base=section"`
+		}{}), ErrInvalidTag, "Invalid option in `offset` tag: base=section."},
+		{reflect.TypeOf(wrongRefMethod{}), ErrTagReference, "Invalid signature of method 'Length' on '*bingo.wrongRefMethod'. Referenced from a `len` tag"},
+		{reflect.TypeOf(badPredicate{}), ErrTagReference, "Invalid signature of method 'HasExtra'"},
+		{reflect.TypeOf(struct {
+			Extra uint8 `if:"Version >= (2"`
+		}{}), ErrInvalidTag, "Invalid expression in `if` tag: Version >= (2."},
+		{reflect.TypeOf(struct {
+			Blobs []blob `until:"IsLast"`
+		}{}), ErrTagReference, "Method 'IsLast()' for"},
+		{reflect.TypeOf(wrongHooks{}), ErrTagReference, "Invalid signature of method 'ParseName' on '*bingo.wrongHooks'. Referenced from a `parser` tag in 'Name string'."},
+		{reflect.TypeOf(voidFactory{}), ErrTagReference, "Invalid signature of method 'NewBlock' on '*bingo.voidFactory'. Referenced from a `factory` tag in 'Blocks []bingo.factoryBlock'."},
+	}
+	for _, test := range tests {
+		err := Validate(test.typ)
+		if !errors.Is(err, test.kind) || err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Incorrect error for %v: %v", test.typ, err)
+		}
+	}
+}
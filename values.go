@@ -0,0 +1,43 @@
+package bingo
+
+// WithValue sets a parameter for the hooks of the next parse to read with
+// Value, such as the version of a format given by a container, or the size
+// of a record stored elsewhere:
+//
+//	type versionKey struct{}
+//
+//	p.WithValue(versionKey{}, 3)
+//	err := p.EmitReadStruct(&record)
+//
+//	func (r *Record) HasExtra(p *bingo.Parser) bool {
+//		version, _ := bingo.ValueAs[int](p, versionKey{})
+//		return version >= 2
+//	}
+//
+// As with context.WithValue, keys are compared with == and should be of a
+// type of their own, so that packages can't clash. Values last until the
+// EmitReadStruct or ParseSections call that follows returns, so each parse
+// gets the ones set for it; hooks may set more for those called after them.
+// Records read with Next all get the values set before the first of them,
+// which last until a parse other than Next returns or the parser is Reset.
+// It returns p, for calls to be chained.
+func (p *Parser) WithValue(key, value interface{}) *Parser {
+	if p.values == nil {
+		p.values = make(map[interface{}]interface{})
+	}
+	p.values[key] = value
+	return p
+}
+
+// Value returns the parameter set for key with WithValue, or nil if there's
+// none.
+func (p *Parser) Value(key interface{}) interface{} {
+	return p.values[key]
+}
+
+// ValueAs returns the parameter set for key with WithValue as a T. It returns
+// false if there's none or it's of another type.
+func ValueAs[T any](p *Parser, key interface{}) (T, bool) {
+	value, ok := p.values[key].(T)
+	return value, ok
+}
@@ -0,0 +1,92 @@
+package bingo
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+type versionKey struct{}
+
+type versionedRecord struct {
+	Kind  uint8
+	Extra uint16 `if:"HasExtra"`
+}
+
+func (r *versionedRecord) HasExtra(p *Parser) bool {
+	version, _ := ValueAs[int](p, versionKey{})
+	return version >= 2
+}
+
+func TestValues(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5}
+	p := newParserData(data)
+
+	var r versionedRecord
+	if err := p.WithValue(versionKey{}, 2).EmitReadStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Extra != 0x0302 {
+		t.Errorf("Incorrect record: %+v", r)
+	}
+
+	// The value only applied to the parse it was set for
+	if p.Value(versionKey{}) != nil {
+		t.Error("Expected the value to be dropped:", p.Value(versionKey{}))
+	}
+	r = versionedRecord{}
+	if err := p.EmitReadStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Kind != 4 || r.Extra != 0 {
+		t.Errorf("Incorrect record: %+v", r)
+	}
+
+	p.WithValue(versionKey{}, "2")
+	if _, ok := ValueAs[int](p, versionKey{}); ok {
+		t.Error("Expected a value of another type to be reported")
+	}
+	if s, ok := ValueAs[string](p, versionKey{}); !ok || s != "2" {
+		t.Error("Incorrect value:", s)
+	}
+}
+
+func TestValuesInSections(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 6}
+	p := newParserData(data)
+	var records [2]versionedRecord
+	err := p.WithValue(versionKey{}, 2).ParseSections(
+		SectionSpec{Offset: 0, Size: 3, Dest: &records[0]},
+		SectionSpec{Offset: 3, Size: 3, Dest: &records[1]},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].Extra != 0x0302 || records[1].Extra != 0x0605 {
+		t.Errorf("Incorrect records: %+v", records)
+	}
+
+	// Without the value, the sections are too long for the records
+	err = p.ParseSections(SectionSpec{Offset: 0, Size: 3, Dest: &records[0]})
+	if !errors.Is(err, ErrSizeMismatch) {
+		t.Error("Expected a size mismatch. Got", err)
+	}
+}
+
+func TestValuesWithNext(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5, 6}
+	p := newParserData(data).WithValue(versionKey{}, 2)
+	var records []versionedRecord
+	for {
+		var r versionedRecord
+		if err := p.Next(&r); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 || records[0].Extra != 0x0302 || records[1].Extra != 0x0605 {
+		t.Errorf("Expected the value to apply to every record: %+v", records)
+	}
+}
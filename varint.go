@@ -0,0 +1,81 @@
+package bingo
+
+import (
+	"reflect"
+)
+
+// Reads an integer field tagged with `varint`, which selects its
+// variable-width encoding: leb128 or gitofs.
+func (p *Parser) readVarintField(fieldval reflect.Value, fieldtyp reflect.StructField, encoding string) error {
+	var value uint64
+	var err error
+	switch encoding {
+	case "leb128":
+		value, err = p.readUvarint(fieldtyp)
+	case "gitofs":
+		value, err = p.readGitOffset(fieldtyp)
+	default:
+		return p.errorf(ErrInvalidTag, nil, "Invalid value for `varint` tag: %v. Expected leb128 or gitofs.", encoding)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch fieldval.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value > 1<<63-1 || fieldval.OverflowInt(int64(value)) {
+			return p.errorf(nil, nil, "Error reading varint for '%v %v'. Value %v overflows the field.", fieldtyp.Name, fieldtyp.Type, value)
+		}
+		fieldval.SetInt(int64(value))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if fieldval.OverflowUint(value) {
+			return p.errorf(nil, nil, "Error reading varint for '%v %v'. Value %v overflows the field.", fieldtyp.Name, fieldtyp.Type, value)
+		}
+		fieldval.SetUint(value)
+	default:
+		return p.errorf(ErrUnsupportedType, nil, "Error reading field '%v %v'. The `varint` tag is only supported on integers.", fieldtyp.Name, fieldtyp.Type)
+	}
+	return nil
+}
+
+// Reads an unsigned LEB128 value: 7 bits per byte, least significant group
+// first, with the high bit set on every byte but the last.
+func (p *Parser) readUvarint(fieldtyp reflect.StructField) (uint64, error) {
+	var value uint64
+	var buf [1]byte
+	for shift := uint(0); ; shift += 7 {
+		if err := p.EmitReadFull(buf[:]); err != nil {
+			return 0, err
+		}
+		b := buf[0]
+		if shift == 63 && b > 1 || shift > 63 {
+			return 0, p.errorf(nil, nil, "Error reading varint for '%v %v'. Value overflows 64 bits.", fieldtyp.Name, fieldtyp.Type)
+		}
+		value |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return value, nil
+		}
+	}
+}
+
+// Reads a value in the offset encoding of git packfiles (as used by
+// OFS_DELTA entries). It resembles big-endian LEB128, except that one is
+// added to the accumulated value before each continuation byte is shifted
+// in, so that every value has exactly one representation.
+func (p *Parser) readGitOffset(fieldtyp reflect.StructField) (uint64, error) {
+	var buf [1]byte
+	if err := p.EmitReadFull(buf[:]); err != nil {
+		return 0, err
+	}
+	value := uint64(buf[0] & 0x7F)
+	for buf[0]&0x80 != 0 {
+		if value >= 1<<57 {
+			return 0, p.errorf(nil, nil, "Error reading varint for '%v %v'. Value overflows 64 bits.", fieldtyp.Name, fieldtyp.Type)
+		}
+		if err := p.EmitReadFull(buf[:]); err != nil {
+			return 0, err
+		}
+		value = (value+1)<<7 | uint64(buf[0]&0x7F)
+	}
+	return value, nil
+}
@@ -0,0 +1,67 @@
+package bingo
+
+import (
+	"testing"
+)
+
+func TestVarintFields(t *testing.T) {
+	data := []byte{0xE8, 0x07, // 1000 as LEB128
+		0x86, 0x68, // 1000 as git offset
+		0x80, 0x00, // 128 as git offset
+		0xFF, 0x7F} // 16511 as git offset
+	s := struct {
+		Size   uint32 `varint:"leb128"`
+		Offset int64  `varint:"gitofs"`
+		Min2   uint16 `varint:"gitofs"`
+		Max2   uint16 `varint:"gitofs"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if s.Size != 1000 {
+		t.Error("Error parsing LEB128 varint:", s.Size)
+	}
+	if !(s.Offset == 1000 && s.Min2 == 128 && s.Max2 == 16511) {
+		t.Error("Error parsing git offset varints:", s.Offset, s.Min2, s.Max2)
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
+
+func TestVarintFieldOverflow(t *testing.T) {
+	s := struct {
+		Size uint8 `varint:"leb128"`
+	}{}
+	p := newParserData([]byte{0x80, 0x02})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		if perr, ok := err.(*ParseError); !ok || perr.Error() != "Error reading varint for 'Size uint8'. Value 256 overflows the field." {
+			t.Error("Incorrect error:", err)
+		}
+	} else {
+		t.Error()
+	}
+}
+
+func TestLenPrefixGitOffset(t *testing.T) {
+	data := append([]byte{0x80, 0x00}, make([]byte, 128)...)
+	s := struct {
+		Data []byte `lenprefix:"gitofs"`
+	}{}
+	p := newParserData(data)
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Data) != 128 {
+		t.Error("Error parsing git offset length prefix:", len(s.Data))
+	}
+	if p.offset != uint64(len(data)) {
+		t.Error("Invalid offset:", p.offset)
+	}
+}
@@ -0,0 +1,33 @@
+package bingo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Warnings returns the issues found by the last call to EmitReadStruct that
+// didn't make it fail: nonzero padding, values flagged by a `warn` tag and
+// bytes left unread at the end of the input. Bytes left after a record read
+// with Next belong to the records after it and aren't warned about.
+func (p *Parser) Warnings() []*ParseError {
+	return p.warnings
+}
+
+// Records a warning located at the field the parser is currently reading.
+func (p *Parser) warn(kind error, msg string, args ...interface{}) {
+	perr := p.parseError(kind, nil, fmt.Sprintf(msg, args...))
+	p.warnings = append(p.warnings, perr)
+	p.l.Printf("Warning: %v\n", perr.text)
+	if p.slogEnabled(slog.LevelWarn) {
+		p.slog.LogAttrs(context.Background(), slog.LevelWarn, perr.text, p.slogAttrs(perr.Offset)...)
+	}
+}
+
+// Warns about bytes the struct didn't account for. Only readers that know
+// how much they have left, such as *bytes.Reader, can be checked.
+func (p *Parser) checkTrailingBytes() {
+	if lr, ok := p.src.(interface{ Len() int }); ok && lr.Len() > 0 {
+		p.warn(ErrSizeMismatch, "%v bytes left unread at offset %v.", lr.Len(), p.offset)
+	}
+}
@@ -0,0 +1,103 @@
+package bingo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	s := struct {
+		Version uint8 `warn:"!=1"`
+		Flags   uint8 `pad:"4"`
+		Length  uint16
+	}{}
+	p := newParserData([]byte{1, 0xFF, 0, 7, 0, 12, 0, 0xEE, 0xEE})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Version != 1 || s.Flags != 0xFF || s.Length != 12 {
+		t.Error("Error parsing fields:", s)
+	}
+	warnings := p.Warnings()
+	if len(warnings) != 3 {
+		t.Fatal("Invalid number of warnings:", warnings)
+	}
+	if warnings[0].Error() != "Unexpected value in 'Version uint8' at offset 0: expected != 1, got 1." || warnings[0].FieldPath != "Version" {
+		t.Error("Invalid first warning:", warnings[0], warnings[0].FieldPath)
+	}
+	if warnings[1].Error() != "Nonzero padding after 'Flags uint8' at offset 3: 0x07." || !errors.Is(warnings[1], ErrAssertionFailed) {
+		t.Error("Invalid second warning:", warnings[1])
+	}
+	if warnings[2].Error() != "2 bytes left unread at offset 7." || !errors.Is(warnings[2], ErrSizeMismatch) {
+		t.Error("Invalid third warning:", warnings[2])
+	}
+}
+
+func TestNoWarnings(t *testing.T) {
+	s := struct {
+		Version uint8 `warn:"!=1"`
+		Flags   uint8 `pad:"4"`
+	}{}
+	p := newParserData([]byte{2, 0xFF, 0, 0, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Warnings()) != 0 {
+		t.Error("Unexpected warnings:", p.Warnings())
+	}
+}
+
+func TestFinish(t *testing.T) {
+	s := struct {
+		Length uint16
+	}{}
+	p := newParserData([]byte{1, 0, 0xEE, 0xEE, 0xEE})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Finish()
+	if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrSizeMismatch) || perr.Error() != "3 bytes left unread at offset 2." || perr.Offset != 2 {
+		t.Error("Incorrect error:", err)
+	}
+	if p.Offset() != 5 {
+		t.Error("Incorrect offset after the leftover bytes:", p.Offset())
+	}
+	if err := p.Finish(); err != nil {
+		t.Error("Expected the input to be consumed:", err)
+	}
+}
+
+func TestFinishNotSeekable(t *testing.T) {
+	p := NewParser(io.MultiReader(bytes.NewReader([]byte{1, 0, 0xEE, 0xEE})), LittleEndian, Default)
+	s := struct{ Length uint16 }{}
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Finish()
+	if perr, ok := err.(*ParseError); !ok || !errors.Is(perr, ErrSizeMismatch) || perr.Error() != "2 bytes left unread at offset 2." {
+		t.Error("Incorrect error:", err)
+	}
+	if p.Offset() != 4 {
+		t.Error("Incorrect offset after the leftover bytes:", p.Offset())
+	}
+}
+
+func TestFinishComplete(t *testing.T) {
+	s := struct {
+		Length uint16
+	}{}
+	p := newParserData([]byte{1, 0})
+
+	if err := p.EmitReadStruct(&s); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Finish(); err != nil {
+		t.Error(err)
+	}
+}